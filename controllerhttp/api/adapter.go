@@ -2,6 +2,8 @@ package api
 
 import (
 	"pf2eEngine/game"
+	dice "pf2eEngine/util"
+	"sort"
 	"time"
 )
 
@@ -31,6 +33,7 @@ func StepToEvent(step game.Step, message string) GameEvent {
 			},
 			Roll:   s.Attack.Roll,
 			Result: s.Attack.Result,
+			Record: RollRecordToAPI(s.Attack.Record),
 		}
 
 	case game.AfterAttackStep:
@@ -48,30 +51,125 @@ func StepToEvent(step game.Step, message string) GameEvent {
 
 	case game.BeforeDamageStep:
 		event.Data = DamageEventData{
-			Source: EntityRef{
-				ID:   s.Damage.Source.Id,
-				Name: s.Damage.Source.Name,
-			},
+			Source: entityRef(s.Damage.Source),
 			Target: EntityRef{
 				ID:   s.Damage.Target.Id,
 				Name: s.Damage.Target.Name,
 			},
 			// Sum up all damage amounts for a simplified representation
-			Amount: sumDamageAmount(s.Damage.Amount),
+			Amount:    sumDamageAmount(s.Damage.Amount),
+			Rolls:     rollRecordsToAPI(s.Damage.Rolls),
+			Fortune:   s.Damage.Fortune.String(),
+			Discarded: rollRecordsToAPI(s.Damage.Discarded),
 		}
 
 	case game.AfterDamageStep:
 		event.Data = DamageEventData{
-			Source: EntityRef{
-				ID:   s.Damage.Source.Id,
-				Name: s.Damage.Source.Name,
-			},
+			Source: entityRef(s.Damage.Source),
 			Target: EntityRef{
 				ID:   s.Damage.Target.Id,
 				Name: s.Damage.Target.Name,
 			},
-			Blocked: s.Damage.Blocked,
-			Taken:   s.Damage.Taken,
+			Blocked:     s.Damage.Blocked,
+			Taken:       s.Damage.Taken,
+			Adjustments: damageAdjustmentsToAPI(s.Damage.Adjustments),
+		}
+
+	case game.BeforeSaveStep:
+		event.Data = SaveEventData{
+			Entity: entityRef(s.Save.Entity),
+			Source: optionalEntityRef(s.Save.Source),
+			Save:   string(s.Save.Type),
+			DC:     s.Save.DC,
+		}
+
+	case game.AfterSaveStep:
+		event.Data = SaveEventData{
+			Entity: entityRef(s.Save.Entity),
+			Source: optionalEntityRef(s.Save.Source),
+			Save:   string(s.Save.Type),
+			DC:     s.Save.DC,
+			Roll:   s.Save.Roll,
+			Result: s.Save.Result,
+			Degree: s.Save.Degree.String(),
+			Record: RollRecordToAPI(s.Save.Record),
+		}
+
+	case game.BeforeCheckStep:
+		event.Data = CheckEventData{
+			Roller: entityRef(s.Check.Roller),
+			Target: optionalEntityRef(s.Check.Target),
+			Check:  s.Check.Name,
+			DC:     s.Check.DC,
+		}
+
+	case game.AfterCheckStep:
+		event.Data = CheckEventData{
+			Roller: entityRef(s.Check.Roller),
+			Target: optionalEntityRef(s.Check.Target),
+			Check:  s.Check.Name,
+			DC:     s.Check.DC,
+			Roll:   s.Check.Roll,
+			Result: s.Check.Result,
+			Degree: s.Check.Degree.String(),
+			Record: RollRecordToAPI(s.Check.Record),
+		}
+
+	case game.BeforeHealStep:
+		event.Data = HealEventData{
+			Source: optionalEntityRef(s.Healing.Source),
+			Target: entityRef(s.Healing.Target),
+			Amount: s.Healing.Amount,
+			Rolls:  rollRecordsToAPI(s.Healing.Rolls),
+		}
+
+	case game.AfterHealStep:
+		event.Data = HealEventData{
+			Source: optionalEntityRef(s.Healing.Source),
+			Target: entityRef(s.Healing.Target),
+			Amount: s.Healing.Amount,
+			Healed: s.Healing.Healed,
+		}
+
+	case game.PersistentDamageStep:
+		event.Data = PersistentDamageEventData{
+			Entity: entityRef(s.Entity),
+			Damage: s.Condition.Damage.String(),
+			Type:   string(s.Condition.Damage.Type),
+			Source: s.Condition.Source,
+		}
+
+	case game.StartActionStep:
+		event.Data = actionEventData(s.Actor, s.Action)
+
+	case game.EndActionStep:
+		event.Data = actionEventData(s.Actor, s.Action)
+
+	case game.MoveStep:
+		event.Data = MoveEventData{
+			Entity: entityRef(s.Entity),
+			From:   [2]int{s.From.X, s.From.Y},
+			To:     [2]int{s.To.X, s.To.Y},
+			Action: s.Action,
+		}
+
+	case game.AreaStep:
+		squares := make([][2]int, 0, len(s.Squares))
+		for _, p := range s.Squares {
+			squares = append(squares, [2]int{p.X, p.Y})
+		}
+		targets := make([]EntityRef, 0, len(s.Targets))
+		for _, t := range s.Targets {
+			targets = append(targets, entityRef(t))
+		}
+		event.Data = AreaEventData{
+			Source:    optionalEntityRef(s.Source),
+			Shape:     string(s.Template.Shape),
+			Size:      s.Template.Size,
+			Origin:    [2]int{s.Template.Origin.X, s.Template.Origin.Y},
+			Direction: string(s.Template.Direction),
+			Squares:   squares,
+			Targets:   targets,
 		}
 
 	case *game.StartTurnStep:
@@ -98,6 +196,89 @@ func StepToEvent(step game.Step, message string) GameEvent {
 	return event
 }
 
+// actionEventData describes an action an entity is taking
+func actionEventData(actor *game.Entity, action *game.Action) ActionEventData {
+	return ActionEventData{
+		Entity:    entityRef(actor),
+		Action:    action.Name,
+		Traits:    actionTraitsToAPI(action.Traits),
+		Disrupted: action.Disrupted,
+	}
+}
+
+// actionTraitsToAPI converts action traits to their names
+func actionTraitsToAPI(traits []game.ActionTrait) []string {
+	if len(traits) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(traits))
+	for _, t := range traits {
+		names = append(names, string(t))
+	}
+	return names
+}
+
+// entityRef converts an entity to a reference; a nil entity becomes an empty reference
+func entityRef(e *game.Entity) EntityRef {
+	if e == nil {
+		return EntityRef{}
+	}
+	return EntityRef{ID: e.Id, Name: e.Name}
+}
+
+// targetRulesToAPI converts a card's target rules, if it has any
+func targetRulesToAPI(rules *game.TargetRules) *TargetRulesData {
+	if rules == nil {
+		return nil
+	}
+	return &TargetRulesData{
+		MaxTargets: rules.MaxTargets,
+		Distinct:   rules.Distinct,
+		Range:      rules.Range,
+	}
+}
+
+// optionalEntityRef converts an entity that may be nil to a reference
+func optionalEntityRef(e *game.Entity) *EntityRef {
+	if e == nil {
+		return nil
+	}
+	ref := entityRef(e)
+	return &ref
+}
+
+// RollRecordToAPI converts a roll record to its API representation
+func RollRecordToAPI(record dice.RollRecord) *RollRecordData {
+	if len(record.Dice) == 0 && len(record.Modifiers) == 0 {
+		return nil
+	}
+	data := &RollRecordData{
+		Expression: record.Expression,
+		Dice:       make([]DieResultData, 0, len(record.Dice)),
+		Total:      record.Total,
+		Summary:    record.String(),
+		Fortune:    record.Fortune.String(),
+		Discarded:  rollRecordsToAPI(record.Discarded),
+	}
+	for _, d := range record.Dice {
+		data.Dice = append(data.Dice, DieResultData{Sides: d.Sides, Value: d.Value, Dropped: d.Dropped})
+	}
+	for _, m := range record.Modifiers {
+		data.Modifiers = append(data.Modifiers, ModifierData{Source: m.Source, Value: m.Value})
+	}
+	return data
+}
+
+func rollRecordsToAPI(records []dice.RollRecord) []RollRecordData {
+	data := make([]RollRecordData, 0, len(records))
+	for _, r := range records {
+		if converted := RollRecordToAPI(r); converted != nil {
+			data = append(data, *converted)
+		}
+	}
+	return data
+}
+
 // Sum up damage amounts from multiple damage types
 func sumDamageAmount(damageMap map[game.DamageType]game.DamageAmount) int {
 	total := 0
@@ -118,10 +299,32 @@ func mapStepTypeToEventType(stepType game.StepType) string {
 		return EventTypeDamage
 	case game.AfterDamage:
 		return EventTypeDamageResult
+	case game.BeforeSave:
+		return EventTypeSave
+	case game.AfterSave:
+		return EventTypeSaveResult
+	case game.BeforeCheck:
+		return EventTypeCheck
+	case game.AfterCheck:
+		return EventTypeCheckResult
+	case game.BeforeHeal:
+		return EventTypeHeal
+	case game.AfterHeal:
+		return EventTypeHealResult
+	case game.PersistentDamageTick:
+		return EventTypePersistent
 	case game.StartTurn:
 		return EventTypeTurnStart
 	case game.EndTurn:
 		return EventTypeTurnEnd
+	case game.StartAction:
+		return EventTypeActionStart
+	case game.EndAction:
+		return EventTypeActionComplete
+	case game.EntityMove:
+		return EventTypeEntityMove
+	case game.AreaEffect:
+		return EventTypeArea
 	default:
 		return EventTypeInfo
 	}
@@ -133,8 +336,16 @@ func GameStateToAPIState(gs *game.GameState) GameState {
 		Entities:   make([]EntityState, 0),
 		GridWidth:  gs.Grid.Width,
 		GridHeight: gs.Grid.Height,
-		Round:      0, // Round is not explicitly tracked in the current game state
+		Round:      gs.Round,
+		Seed:       gs.Seed,
+	}
+	for pos := range gs.Grid.Walls {
+		apiState.Walls = append(apiState.Walls, [2]int{pos.X, pos.Y})
 	}
+	sort.Slice(apiState.Walls, func(i, j int) bool {
+		a, b := apiState.Walls[i], apiState.Walls[j]
+		return a[1] < b[1] || (a[1] == b[1] && a[0] < b[0])
+	})
 
 	// Get the current entity ID if there is one
 	currentEntity := gs.GetCurrentTurnEntity()
@@ -180,6 +391,8 @@ func EntityToAPIEntity(entity *game.Entity, grid *game.Grid) EntityState {
 			Description: card.Description,
 			ActionCost:  actionCost,
 			Type:        string(card.Type),
+			Traits:      actionTraitsToAPI(card.Traits),
+			Targets:     targetRulesToAPI(card.Targets),
 		})
 	}
 
@@ -198,16 +411,172 @@ func EntityToAPIEntity(entity *game.Entity, grid *game.Grid) EntityState {
 		maxHP = entity.MaxHP
 	}
 
+	saves := make(map[string]int, len(game.SaveTypes))
+	for _, t := range game.SaveTypes {
+		saves[string(t)] = entity.SaveModifier(t)
+	}
+	statistics := []game.Statistic{game.StatAC, game.StatPerception}
+	for _, t := range game.SaveTypes {
+		statistics = append(statistics, game.Statistic(t))
+	}
+	// Entities with attributes have a modifier in every skill, others only in
+	// the skills they were given
+	skills := make(map[string]int, len(entity.Skills))
+	if entity.Attributes != nil {
+		statistics = append(statistics, game.StatClassDC)
+		for _, skill := range game.Skills {
+			skills[string(skill)] = entity.SkillModifier(skill)
+			statistics = append(statistics, game.Statistic(skill))
+		}
+	} else {
+		for skill := range entity.Skills {
+			skills[string(skill)] = entity.SkillModifier(skill)
+			statistics = append(statistics, game.Statistic(skill))
+		}
+	}
+	if entity.Spellcasting != nil {
+		statistics = append(statistics, game.StatSpellAttack, game.StatSpellDC)
+	}
+	var immunities []string
+	for t, immune := range entity.Immunities {
+		if immune {
+			immunities = append(immunities, string(t))
+		}
+	}
+	sort.Strings(immunities)
+	armor := ""
+	if entity.Armor != nil {
+		armor = entity.Armor.Name
+	}
+
+	breakdowns := make(map[string]StatisticData, len(statistics))
+	for _, stat := range statistics {
+		breakdowns[string(stat)] = BreakdownToAPI(entity.Breakdown(stat))
+	}
+
 	return EntityState{
 		ID:                 entity.Id,
 		Name:               entity.Name,
 		HP:                 entity.HP,
 		MaxHP:              maxHP,
-		AC:                 entity.AC,
+		TempHP:             entity.TempHP,
+		AC:                 entity.ArmorClass(),
+		Armor:              armor,
+		Speed:              entity.LandSpeed(),
+		Shield:             shieldToAPI(entity.Shield),
+		Spellcasting:       spellcastingToAPI(entity),
 		ActionsRemaining:   entity.ActionsRemaining,
 		ReactionsRemaining: entity.ReactionsRemaining,
 		Faction:            factionStr,
 		ActionCards:        actionCards,
 		Position:           pos,
+		Saves:              saves,
+		Skills:             skills,
+		Perception:         entity.Modifier(game.StatPerception),
+		Level:              entity.Level,
+		Statistics:         breakdowns,
+		Conditions:         conditionsToAPI(entity.Conditions),
+		State:              string(entity.LifeState()),
+		Resistances:        damageTableToAPI(entity.Resistances),
+		Weaknesses:         damageTableToAPI(entity.Weaknesses),
+		Immunities:         immunities,
+	}
+}
+
+// shieldToAPI converts a shield to its API representation, or nil for no shield
+func shieldToAPI(s *game.Shield) *ShieldData {
+	if s == nil {
+		return nil
+	}
+	return &ShieldData{
+		Name:            s.Name,
+		ACBonus:         s.ACBonus,
+		Hardness:        s.Hardness,
+		HP:              s.HP,
+		MaxHP:           s.MaxHP,
+		BrokenThreshold: s.BrokenThreshold,
+		Raised:          s.Raised,
+		Broken:          s.IsBroken(),
+	}
+}
+
+// spellcastingToAPI converts the entity's spellcasting to its API representation, or nil for non-casters
+func spellcastingToAPI(entity *game.Entity) *SpellcastingData {
+	sc := entity.Spellcasting
+	if sc == nil {
+		return nil
+	}
+	data := &SpellcastingData{
+		Tradition:      string(sc.Tradition),
+		Type:           string(sc.Type),
+		SpellAttack:    entity.SpellAttackBreakdown().Total,
+		SpellDC:        entity.SpellDC(),
+		Slots:          make(map[int]int, len(sc.Slots)),
+		FocusPoints:    sc.FocusPoints,
+		MaxFocusPoints: sc.MaxFocusPoints,
+	}
+	for rank, n := range sc.Slots {
+		data.Slots[rank] = n
+	}
+	for _, p := range sc.Prepared {
+		data.Prepared = append(data.Prepared, PreparedSpellData{Spell: p.Spell.Name, Rank: p.Rank, Expended: p.Expended})
+	}
+	return data
+}
+
+func damageAdjustmentsToAPI(adjustments []game.DamageAdjustment) []DamageAdjustmentData {
+	data := make([]DamageAdjustmentData, len(adjustments))
+	for i, a := range adjustments {
+		data[i] = DamageAdjustmentData{Type: string(a.Type), Kind: string(a.Kind), Against: string(a.Against), Value: a.Value}
+	}
+	return data
+}
+
+// damageTableToAPI converts a resistance or weakness table to its API representation
+func damageTableToAPI(table map[game.DamageType]int) map[string]int {
+	if len(table) == 0 {
+		return nil
+	}
+	data := make(map[string]int, len(table))
+	for t, value := range table {
+		data[string(t)] = value
 	}
-}
\ No newline at end of file
+	return data
+}
+
+func conditionsToAPI(conditions []game.Condition) []ConditionData {
+	if len(conditions) == 0 {
+		return nil
+	}
+	data := make([]ConditionData, len(conditions))
+	for i, c := range conditions {
+		data[i] = ConditionData{Name: string(c.Name), Value: c.Value, Duration: c.Duration, Source: c.Source}
+		if c.Damage != nil {
+			data[i].Damage = c.Damage.String()
+		}
+	}
+	return data
+}
+
+// BreakdownToAPI converts a statistic's breakdown to its API representation
+func BreakdownToAPI(b game.Breakdown) StatisticData {
+	modifiers := make([]ModifierData, len(b.Modifiers))
+	for i, m := range b.Modifiers {
+		modifiers[i] = ModifierData{Source: m.Source, Type: string(m.Type), Value: m.Value}
+	}
+	return StatisticData{Base: b.Base, Modifiers: modifiers, Total: b.Total}
+}
+
+// AttackOddsToAPI converts calculated attack odds to their API representation
+func AttackOddsToAPI(odds game.AttackOdds) AttackOddsData {
+	return AttackOddsData{
+		MAP:             odds.MAP,
+		Bonus:           odds.Bonus,
+		CriticalSuccess: odds.Degrees[game.CriticalSuccess],
+		Success:         odds.Degrees[game.Success],
+		Failure:         odds.Degrees[game.Failure],
+		CriticalFailure: odds.Degrees[game.CriticalFailure],
+		HitChance:       odds.HitChance(),
+		ExpectedDamage:  odds.ExpectedDamage,
+	}
+}
@@ -39,25 +39,104 @@ type GameEvent struct {
 
 // ActionCardRef represents a reference to an action card
 type ActionCardRef struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	ActionCost  int       `json:"actionCost"`
-	Type        string    `json:"type,omitempty"`
+	ID          uuid.UUID        `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	ActionCost  int              `json:"actionCost"`
+	Type        string           `json:"type,omitempty"`
+	Traits      []string         `json:"traits,omitempty"`
+	Targets     *TargetRulesData `json:"targets,omitempty"` // Set for cards that take "targetIDs" rather than a "targetID"
+}
+
+// TargetRulesData represents the limits on the targets of a multi-target card
+type TargetRulesData struct {
+	MaxTargets int  `json:"maxTargets,omitempty"` // Zero for no limit
+	Distinct   bool `json:"distinct"`
+	Range      int  `json:"range,omitempty"` // In feet; zero for no limit
 }
 
 // EntityState represents the complete state of an entity
 type EntityState struct {
-	ID                 uuid.UUID       `json:"id"`
-	Name               string          `json:"name"`
-	HP                 int             `json:"hp"`
-	MaxHP              int             `json:"maxHp"` // Added to ensure frontend knows the max HP
-	AC                 int             `json:"ac"`
-	ActionsRemaining   int             `json:"actionsRemaining"`
-	ReactionsRemaining int             `json:"reactionsRemaining"`
-	Faction            string          `json:"faction"`
-	ActionCards        []ActionCardRef `json:"actionCards,omitempty"`
-	Position           [2]int          `json:"position,omitempty"`
+	ID                 uuid.UUID                `json:"id"`
+	Name               string                   `json:"name"`
+	HP                 int                      `json:"hp"`
+	MaxHP              int                      `json:"maxHp"` // Added to ensure frontend knows the max HP
+	TempHP             int                      `json:"tempHp,omitempty"`
+	AC                 int                      `json:"ac"`
+	Armor              string                   `json:"armor,omitempty"`
+	Speed              int                      `json:"speed"` // Land Speed in feet after armor
+	Shield             *ShieldData              `json:"shield,omitempty"`
+	Spellcasting       *SpellcastingData        `json:"spellcasting,omitempty"`
+	ActionsRemaining   int                      `json:"actionsRemaining"`
+	ReactionsRemaining int                      `json:"reactionsRemaining"`
+	Faction            string                   `json:"faction"`
+	ActionCards        []ActionCardRef          `json:"actionCards,omitempty"`
+	Position           [2]int                   `json:"position,omitempty"`
+	Saves              map[string]int           `json:"saves,omitempty"`  // Save modifiers keyed by FORTITUDE, REFLEX and WILL
+	Skills             map[string]int           `json:"skills,omitempty"` // Skill modifiers keyed by skill, e.g. ATHLETICS
+	Perception         int                      `json:"perception"`
+	Level              int                      `json:"level"`
+	Statistics         map[string]StatisticData `json:"statistics,omitempty"` // Breakdowns keyed by statistic, e.g. AC, WILL or ATHLETICS
+	Conditions         []ConditionData          `json:"conditions,omitempty"`
+	Resistances        map[string]int           `json:"resistances,omitempty"`
+	Weaknesses         map[string]int           `json:"weaknesses,omitempty"`
+	Immunities         []string                 `json:"immunities,omitempty"`
+	State              string                   `json:"state"` // CONSCIOUS, UNCONSCIOUS, DYING or DEAD
+}
+
+// ConditionData represents a condition an entity has, e.g. frightened 2
+type ConditionData struct {
+	Name     string `json:"name"`               // e.g. FRIGHTENED or OFF_GUARD
+	Value    int    `json:"value,omitempty"`    // For valued conditions
+	Duration int    `json:"duration,omitempty"` // Rounds left; omitted when it lasts until removed
+	Source   string `json:"source,omitempty"`
+	Damage   string `json:"damage,omitempty"` // Persistent damage only, e.g. "1d6 persistent fire"
+}
+
+// PersistentDamageEventData represents persistent damage being dealt at the end of a turn
+type PersistentDamageEventData struct {
+	Entity EntityRef `json:"entity"`
+	Damage string    `json:"damage"` // e.g. "1d6 persistent fire"
+	Type   string    `json:"type"`
+	Source string    `json:"source,omitempty"`
+}
+
+// ShieldData represents the shield an entity holds
+type ShieldData struct {
+	Name            string `json:"name"`
+	ACBonus         int    `json:"acBonus"`
+	Hardness        int    `json:"hardness"`
+	HP              int    `json:"hp"`
+	MaxHP           int    `json:"maxHp"`
+	BrokenThreshold int    `json:"brokenThreshold"`
+	Raised          bool   `json:"raised"`
+	Broken          bool   `json:"broken"`
+}
+
+// SpellcastingData represents an entity's spellcasting and the slots and focus points it has left
+type SpellcastingData struct {
+	Tradition      string              `json:"tradition"`
+	Type           string              `json:"type"` // PREPARED or SPONTANEOUS
+	SpellAttack    int                 `json:"spellAttack"`
+	SpellDC        int                 `json:"spellDc"`
+	Slots          map[int]int         `json:"slots,omitempty"` // By rank: left to cast for spontaneous casters, in total for prepared ones
+	Prepared       []PreparedSpellData `json:"prepared,omitempty"`
+	FocusPoints    int                 `json:"focusPoints,omitempty"`
+	MaxFocusPoints int                 `json:"maxFocusPoints,omitempty"`
+}
+
+// PreparedSpellData represents a spell prepared in a slot
+type PreparedSpellData struct {
+	Spell    string `json:"spell"`
+	Rank     int    `json:"rank"`
+	Expended bool   `json:"expended,omitempty"`
+}
+
+// StatisticData shows how a statistic's total was reached
+type StatisticData struct {
+	Base      int            `json:"base,omitempty"` // 10 for AC and class DC
+	Modifiers []ModifierData `json:"modifiers,omitempty"`
+	Total     int            `json:"total"`
 }
 
 // GameState represents the entire game state
@@ -66,14 +145,51 @@ type GameState struct {
 	CurrentTurn *uuid.UUID    `json:"currentTurn,omitempty"`
 	GridWidth   int           `json:"gridWidth"`
 	GridHeight  int           `json:"gridHeight"`
+	Walls       [][2]int      `json:"walls,omitempty"` // Solid squares, which block movement and line of effect
 	Round       int           `json:"round"`
+	Seed        int64         `json:"seed,string"` // String-encoded: seeds exceed JavaScript's safe integer range
 }
 
 // CommandRequest represents a command sent from the frontend to the backend
 type CommandRequest struct {
 	EntityID     uuid.UUID              `json:"entity_id"`
 	ActionCardID uuid.UUID              `json:"action_card_id"`
-	Params       map[string]interface{} `json:"params"`
+	Params       map[string]interface{} `json:"params"` // e.g. "targetID", "targetIDs", or "position" as [x, y]
+}
+
+// Client message types. Messages without a type are commands.
+const (
+	MessageTypeCommand          = "COMMAND"
+	MessageTypeRollResult       = "ROLL_RESULT"
+	MessageTypeReactionResponse = "REACTION_RESPONSE"
+	MessageTypeControl          = "CONTROL"
+)
+
+// ClientMessage holds the fields shared by every message a client sends over the WebSocket
+type ClientMessage struct {
+	Type      string    `json:"type,omitempty"`
+	RequestID uuid.UUID `json:"request_id,omitempty"`
+}
+
+// ControlMessage takes control of an entity, so that its roll and reaction
+// prompts are sent to this client alone and only this client can answer them
+type ControlMessage struct {
+	ClientMessage
+	EntityID uuid.UUID `json:"entity_id"`
+}
+
+// RollResultMessage answers a ROLL_REQUEST with the value of a physical die
+type RollResultMessage struct {
+	ClientMessage
+	Value int `json:"value"`
+}
+
+// ReactionResponseMessage answers a REACTION_PROMPT. Use says whether to
+// react and Choice picks which of the offered reactions to take.
+type ReactionResponseMessage struct {
+	ClientMessage
+	Use    bool `json:"use"`
+	Choice int  `json:"choice,omitempty"`
 }
 
 // CommandResponse represents a response to a command
@@ -83,25 +199,141 @@ type CommandResponse struct {
 	Error   string `json:"error,omitempty"`
 }
 
+// OddsRequest asks for the exact odds of an attack against a target AC.
+// Damage is a dice expression such as "1d8+4 slashing".
+type OddsRequest struct {
+	Bonus   int    `json:"bonus"`
+	Damage  string `json:"damage"`
+	AC      int    `json:"ac"`
+	Fortune string `json:"fortune,omitempty"` // "fortune", "misfortune" or empty
+}
+
+// AttackOddsData represents the outcome distribution of one attack in a turn
+type AttackOddsData struct {
+	MAP             int     `json:"map"`
+	Bonus           int     `json:"bonus"`
+	CriticalSuccess float64 `json:"criticalSuccess"`
+	Success         float64 `json:"success"`
+	Failure         float64 `json:"failure"`
+	CriticalFailure float64 `json:"criticalFailure"`
+	HitChance       float64 `json:"hitChance"`
+	ExpectedDamage  float64 `json:"expectedDamage"`
+}
+
+// OddsResponse holds the odds of the first, second and third attack of a turn
+type OddsResponse struct {
+	AC      int              `json:"ac"`
+	Attacks []AttackOddsData `json:"attacks"`
+}
+
 // Specialized event data structures
 
+// DieResultData represents a single die face within a roll
+type DieResultData struct {
+	Sides   int  `json:"sides"`
+	Value   int  `json:"value"`
+	Dropped bool `json:"dropped,omitempty"`
+}
+
+// ModifierData represents a modifier and where it came from
+type ModifierData struct {
+	Source string `json:"source,omitempty"`
+	Type   string `json:"type,omitempty"` // How the modifier stacks, e.g. ITEM or STATUS
+	Value  int    `json:"value"`
+}
+
+// RollRecordData represents a complete roll: every die face, the modifiers and the total
+type RollRecordData struct {
+	Expression string           `json:"expression"`
+	Dice       []DieResultData  `json:"dice"`
+	Modifiers  []ModifierData   `json:"modifiers,omitempty"`
+	Total      int              `json:"total"`
+	Summary    string           `json:"summary"` // Human-readable form, e.g. "d20=14 +5 (weapon) = 19"
+	Fortune    string           `json:"fortune,omitempty"`
+	Discarded  []RollRecordData `json:"discarded,omitempty"`
+}
+
 // AttackEventData represents an attack event
 type AttackEventData struct {
-	Attacker EntityRef `json:"attacker"`
-	Defender EntityRef `json:"defender"`
-	Roll     int       `json:"roll"`
-	Result   int       `json:"result"`
-	Degree   string    `json:"degree,omitempty"`
+	Attacker EntityRef       `json:"attacker"`
+	Defender EntityRef       `json:"defender"`
+	Roll     int             `json:"roll"`
+	Result   int             `json:"result"`
+	Degree   string          `json:"degree,omitempty"`
+	Record   *RollRecordData `json:"record,omitempty"`
 }
 
 // DamageEventData represents a damage event
 type DamageEventData struct {
-	Source  EntityRef `json:"source"`
-	Target  EntityRef `json:"target"`
-	Amount  int       `json:"amount,omitempty"`
-	Type    string    `json:"type,omitempty"`
-	Blocked int       `json:"blocked,omitempty"`
-	Taken   int       `json:"taken,omitempty"`
+	Source  EntityRef        `json:"source"`
+	Target  EntityRef        `json:"target"`
+	Amount  int              `json:"amount,omitempty"`
+	Type    string           `json:"type,omitempty"`
+	Blocked int              `json:"blocked,omitempty"`
+	Taken   int              `json:"taken,omitempty"`
+	Rolls   []RollRecordData `json:"rolls,omitempty"`
+	Fortune string           `json:"fortune,omitempty"`
+	// Discarded holds the damage rolls thrown away by fortune or misfortune
+	Discarded   []RollRecordData       `json:"discarded,omitempty"`
+	Adjustments []DamageAdjustmentData `json:"adjustments,omitempty"`
+}
+
+// DamageAdjustmentData represents an immunity, weakness or resistance that changed damage
+type DamageAdjustmentData struct {
+	Type    string `json:"type"`    // Damage type adjusted, e.g. FIRE
+	Kind    string `json:"kind"`    // IMMUNITY, WEAKNESS or RESISTANCE
+	Against string `json:"against"` // Entry that applied, e.g. FIRE, PHYSICAL or ALL
+	Value   int    `json:"value"`   // Change to the damage
+}
+
+// SaveEventData represents a saving throw event
+type SaveEventData struct {
+	Entity EntityRef       `json:"entity"`
+	Source *EntityRef      `json:"source,omitempty"`
+	Save   string          `json:"save"`
+	DC     int             `json:"dc"`
+	Roll   int             `json:"roll,omitempty"`
+	Result int             `json:"result,omitempty"`
+	Degree string          `json:"degree,omitempty"`
+	Record *RollRecordData `json:"record,omitempty"`
+}
+
+// HealEventData represents a healing event
+type HealEventData struct {
+	Source *EntityRef       `json:"source,omitempty"`
+	Target EntityRef        `json:"target"`
+	Amount int              `json:"amount"`
+	Healed int              `json:"healed,omitempty"` // HP restored once capped by the target's max HP
+	Rolls  []RollRecordData `json:"rolls,omitempty"`
+}
+
+// CheckEventData represents a skill, Perception or flat check event
+type CheckEventData struct {
+	Roller EntityRef       `json:"roller"`
+	Target *EntityRef      `json:"target,omitempty"`
+	Check  string          `json:"check"`
+	DC     int             `json:"dc"`
+	Roll   int             `json:"roll,omitempty"`
+	Result int             `json:"result,omitempty"`
+	Degree string          `json:"degree,omitempty"`
+	Record *RollRecordData `json:"record,omitempty"`
+}
+
+// RollRequestData asks the player controlling an entity to roll a die and report the result
+type RollRequestData struct {
+	RequestID uuid.UUID `json:"requestId"`
+	Entity    EntityRef `json:"entity"`
+	Sides     int       `json:"sides"`
+	Error     string    `json:"error,omitempty"` // Why the previous answer was rejected
+}
+
+// ReactionPromptData asks the player controlling an entity whether to take a reaction
+type ReactionPromptData struct {
+	RequestID uuid.UUID `json:"requestId"`
+	Entity    EntityRef `json:"entity"`
+	Trigger   string    `json:"trigger"`           // What the entity would react to
+	Options   []string  `json:"options"`           // The reactions on offer, e.g. "Shield Block"
+	Timeout   int       `json:"timeout,omitempty"` // Seconds before the reaction is declined; zero waits forever
 }
 
 // TurnEventData represents a turn event
@@ -109,6 +341,33 @@ type TurnEventData struct {
 	Entity EntityRef `json:"entity"`
 }
 
+// ActionEventData represents an entity starting or completing an action
+type ActionEventData struct {
+	Entity    EntityRef `json:"entity"`
+	Action    string    `json:"action"`
+	Traits    []string  `json:"traits,omitempty"`
+	Disrupted bool      `json:"disrupted,omitempty"`
+}
+
+// MoveEventData represents an entity about to move one square during a move action
+type MoveEventData struct {
+	Entity EntityRef `json:"entity"`
+	From   [2]int    `json:"from"`
+	To     [2]int    `json:"to"`
+	Action string    `json:"action,omitempty"` // The move action, e.g. "Stride"
+}
+
+// AreaEventData represents an area effect placed on the grid
+type AreaEventData struct {
+	Source    *EntityRef  `json:"source,omitempty"`
+	Shape     string      `json:"shape"`
+	Size      int         `json:"size"` // In feet
+	Origin    [2]int      `json:"origin"`
+	Direction string      `json:"direction,omitempty"` // Which way a cone or line points, e.g. "NE"
+	Squares   [][2]int    `json:"squares"`
+	Targets   []EntityRef `json:"targets"`
+}
+
 // GameSetupData represents initial game setup data
 type GameSetupData struct {
 	GridWidth  int           `json:"gridWidth"`
@@ -133,5 +392,16 @@ const (
 	EventTypeRoundEnd       = "ROUND_END"
 	EventTypeEntityMove     = "ENTITY_MOVE"
 	EventTypeEntityStatus   = "ENTITY_STATUS"
+	EventTypeActionStart    = "ACTION_START"
 	EventTypeActionComplete = "ACTION_COMPLETE"
-)
\ No newline at end of file
+	EventTypeRollRequest    = "ROLL_REQUEST"
+	EventTypeReactionPrompt = "REACTION_PROMPT"
+	EventTypeSave           = "SAVE"
+	EventTypeSaveResult     = "SAVE_RESULT"
+	EventTypeCheck          = "CHECK"
+	EventTypeCheckResult    = "CHECK_RESULT"
+	EventTypeHeal           = "HEAL"
+	EventTypeHealResult     = "HEAL_RESULT"
+	EventTypePersistent     = "PERSISTENT_DAMAGE"
+	EventTypeArea           = "AREA"
+)
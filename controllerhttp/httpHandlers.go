@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"pf2eEngine/controllerhttp/api"
+	"pf2eEngine/game"
+	dice "pf2eEngine/util"
 	"strconv"
 	"time"
 )
@@ -142,4 +144,63 @@ func (cs *ControllerServer) GameStateHandler(w http.ResponseWriter, r *http.Requ
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(event)
-}
\ No newline at end of file
+}
+
+// Limits on odds requests, which are worked out exactly and so cost time in
+// proportion to the dice involved
+const (
+	maxOddsRequestBytes = 4 << 10
+	maxOddsDice         = 200 // Dice across every damage roll of the expression
+)
+
+// OddsHandler processes POST requests for the exact odds and expected damage
+// of an attack at each multiple attack penalty
+func (cs *ControllerServer) OddsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST requests are allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request api.OddsRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxOddsRequestBytes)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	damage, err := game.ParseDamage(request.Damage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	count := 0
+	for _, roll := range damage {
+		count += roll.Count
+	}
+	if count > maxOddsDice {
+		http.Error(w, fmt.Sprintf("Too many dice: %d (max: %d)", count, maxOddsDice), http.StatusBadRequest)
+		return
+	}
+
+	var fortune dice.Fortune
+	switch request.Fortune {
+	case "":
+		fortune = dice.NoFortune
+	case "fortune":
+		fortune = dice.FortuneRoll
+	case "misfortune":
+		fortune = dice.MisfortuneRoll
+	default:
+		http.Error(w, fmt.Sprintf("Invalid fortune %q", request.Fortune), http.StatusBadRequest)
+		return
+	}
+
+	attack := game.BaseAttack{Bonus: request.Bonus, Damage: damage}
+	response := api.OddsResponse{AC: request.AC}
+	for _, odds := range game.CalculateAttackProfile(attack, request.AC, fortune) {
+		response.Attacks = append(response.Attacks, api.AttackOddsToAPI(odds))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
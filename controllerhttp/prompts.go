@@ -0,0 +1,202 @@
+package controllerhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"pf2eEngine/controllerhttp/api"
+	"pf2eEngine/game"
+	"sync"
+	"time"
+)
+
+// errPromptTimeout is returned when a client does not answer a prompt in time
+var errPromptTimeout = errors.New("prompt timed out")
+
+// directMessage is a message for a single WebSocket client
+type directMessage struct {
+	conn *websocket.Conn
+	data []byte
+}
+
+// entityControllers records which WebSocket client controls each entity. A
+// client takes control of an entity by sending a CONTROL message or a command
+// for it, and gives it up when it disconnects.
+type entityControllers struct {
+	mu   sync.Mutex
+	conn map[uuid.UUID]*websocket.Conn
+}
+
+// claim gives the client control of the entity unless another client has it
+func (c *entityControllers) claim(conn *websocket.Conn, entityID uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		c.conn = make(map[uuid.UUID]*websocket.Conn)
+	}
+	if current, ok := c.conn[entityID]; ok && current != conn {
+		return fmt.Errorf("entity %s is controlled by another client", entityID)
+	}
+	c.conn[entityID] = conn
+	return nil
+}
+
+// release gives up control of every entity the client controls
+func (c *entityControllers) release(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, current := range c.conn {
+		if current == conn {
+			delete(c.conn, id)
+		}
+	}
+}
+
+// of returns the client controlling the entity, or nil if none does
+func (c *entityControllers) of(entityID uuid.UUID) *websocket.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn[entityID]
+}
+
+// pendingPrompt is a prompt waiting on an answer
+type pendingPrompt struct {
+	answer chan json.RawMessage
+	from   *websocket.Conn // The only client allowed to answer; nil lets any client
+}
+
+// pendingPrompts tracks questions sent to WebSocket clients that the engine is
+// waiting on, keyed by request ID
+type pendingPrompts struct {
+	mu      sync.Mutex
+	waiting map[uuid.UUID]pendingPrompt
+}
+
+// open registers a prompt that from may answer, or any client if from is nil,
+// and returns the channel its answer arrives on
+func (p *pendingPrompts) open(id uuid.UUID, from *websocket.Conn) chan json.RawMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.waiting == nil {
+		p.waiting = make(map[uuid.UUID]pendingPrompt)
+	}
+	answer := make(chan json.RawMessage, 1)
+	p.waiting[id] = pendingPrompt{answer: answer, from: from}
+	return answer
+}
+
+// close stops waiting for a prompt
+func (p *pendingPrompts) close(id uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.waiting, id)
+}
+
+// resolve delivers a client's answer to the prompt waiting on it, refusing
+// answers from clients the prompt wasn't meant for
+func (p *pendingPrompts) resolve(id uuid.UUID, conn *websocket.Conn, answer json.RawMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prompt, ok := p.waiting[id]
+	if !ok {
+		return fmt.Errorf("no pending request %s", id)
+	}
+	if prompt.from != nil && prompt.from != conn {
+		return fmt.Errorf("request %s is for another client", id)
+	}
+	delete(p.waiting, id)
+	prompt.answer <- answer
+	return nil
+}
+
+// ask sends event to the client controlling the entity, or to every client if
+// none does, and waits for the answer to request id. A timeout of zero waits
+// forever.
+func (cs *ControllerServer) ask(id uuid.UUID, entityID uuid.UUID, event api.GameEvent, timeout time.Duration) (json.RawMessage, error) {
+	conn := cs.controllers.of(entityID)
+	answer := cs.prompts.open(id, conn)
+	defer cs.prompts.close(id)
+
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	if conn != nil {
+		cs.wsDirect <- directMessage{conn: conn, data: jsonData}
+	} else {
+		cs.wsBroadcast <- jsonData
+	}
+
+	if timeout <= 0 {
+		return <-answer, nil
+	}
+	select {
+	case a := <-answer:
+		return a, nil
+	case <-time.After(timeout):
+		return nil, errPromptTimeout
+	}
+}
+
+// RequestReaction asks the client controlling the entity whether an entity takes one of the
+// reactions on offer. An unanswered prompt declines after the reaction timeout.
+func (cs *ControllerServer) RequestReaction(req game.ReactionRequest) (int, error) {
+	event := api.GameEvent{
+		EventBase: api.EventBase{
+			Type:      api.EventTypeReactionPrompt,
+			Version:   api.CurrentVersion,
+			Timestamp: time.Now(),
+			Message:   fmt.Sprintf("%s, do you want to use a reaction?", req.Entity.Name),
+		},
+		Data: api.ReactionPromptData{
+			RequestID: req.ID,
+			Entity:    api.EntityRef{ID: req.Entity.Id, Name: req.Entity.Name},
+			Trigger:   req.Trigger,
+			Options:   req.Options,
+			Timeout:   int(cs.ReactionTimeout / time.Second),
+		},
+	}
+
+	answer, err := cs.ask(req.ID, req.Entity.Id, event, cs.ReactionTimeout)
+	if err != nil {
+		return game.DeclineReaction, err
+	}
+	var response api.ReactionResponseMessage
+	if err := json.Unmarshal(answer, &response); err != nil {
+		return game.DeclineReaction, err
+	}
+	if !response.Use {
+		return game.DeclineReaction, nil
+	}
+	return response.Choice, nil
+}
+
+// RequestRoll asks the client controlling the entity for the result of a physical die roll
+func (cs *ControllerServer) RequestRoll(req game.RollRequest) (int, error) {
+	event := api.GameEvent{
+		EventBase: api.EventBase{
+			Type:      api.EventTypeRollRequest,
+			Version:   api.CurrentVersion,
+			Timestamp: time.Now(),
+			Message:   fmt.Sprintf("%s, roll a d%d", req.Entity.Name, req.Sides),
+		},
+		Data: api.RollRequestData{
+			RequestID: req.ID,
+			Entity:    api.EntityRef{ID: req.Entity.Id, Name: req.Entity.Name},
+			Sides:     req.Sides,
+			Error:     req.Error,
+		},
+	}
+
+	answer, err := cs.ask(req.ID, req.Entity.Id, event, cs.RollTimeout)
+	if err != nil {
+		return 0, err
+	}
+	var result api.RollResultMessage
+	if err := json.Unmarshal(answer, &result); err != nil {
+		return 0, err
+	}
+	return result.Value, nil
+}
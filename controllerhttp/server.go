@@ -5,6 +5,7 @@ import (
 	"github.com/gorilla/websocket"
 	"pf2eEngine/controllerhttp/api"
 	"pf2eEngine/game"
+	"time"
 )
 
 // ControllerServer handles HTTP requests and WebSocket connections for player-controlled entities.
@@ -17,15 +18,37 @@ type ControllerServer struct {
 	wsClients map[*websocket.Conn]bool
 	// wsBroadcast is a channel for broadcasting messages to all WebSocket clients.
 	wsBroadcast chan []byte
+	// wsDirect is a channel for messages meant for a single WebSocket client.
+	wsDirect chan directMessage
+	// controllers records which WebSocket client controls each entity.
+	controllers entityControllers
+
+	// RollTimeout is how long to wait for a player to enter a physical die
+	// result before rolling for them. Zero waits forever.
+	RollTimeout time.Duration
+	// ReactionTimeout is how long to wait for a player to decide whether to
+	// take a reaction before declining it. Zero waits forever.
+	ReactionTimeout time.Duration
+	// prompts holds the requests sent to clients that are awaiting an answer.
+	prompts pendingPrompts
 }
 
+// Enforce ControllerServer implements the RollPrompter interface
+var _ game.RollPrompter = (*ControllerServer)(nil)
+
+// Enforce ControllerServer implements the ReactionPrompter interface
+var _ game.ReactionPrompter = (*ControllerServer)(nil)
+
 // NewControllerServer initializes a ControllerServer.
 func NewControllerServer(port int, controller *game.PlayerController) *ControllerServer {
 	return &ControllerServer{
-		Port:        port,
-		Controller:  controller,
-		wsClients:   make(map[*websocket.Conn]bool),
-		wsBroadcast: make(chan []byte),
+		Port:            port,
+		Controller:      controller,
+		wsClients:       make(map[*websocket.Conn]bool),
+		wsBroadcast:     make(chan []byte),
+		wsDirect:        make(chan directMessage),
+		RollTimeout:     2 * time.Minute,
+		ReactionTimeout: 30 * time.Second,
 	}
 }
 
@@ -14,6 +14,7 @@ func (cs *ControllerServer) Start() {
 	http.HandleFunc("/api/v1/action", cs.corsMiddleware(cs.HTTPHandler))
 	http.HandleFunc("/api/v1/steps", cs.corsMiddleware(cs.StepsHandler))
 	http.HandleFunc("/api/v1/state", cs.corsMiddleware(cs.GameStateHandler))
+	http.HandleFunc("/api/v1/odds", cs.corsMiddleware(cs.OddsHandler))
 	http.HandleFunc("/ws", cs.WSHandler) // WebSocket doesn't need CORS
 	
 	// Support legacy endpoints for backward compatibility
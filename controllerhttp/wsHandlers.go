@@ -37,6 +37,7 @@ func (cs *ControllerServer) WSHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			fmt.Printf("WebSocket read error: %v\n", err)
 			delete(cs.wsClients, conn)
+			cs.controllers.release(conn)
 			break
 		}
 
@@ -45,6 +46,33 @@ func (cs *ControllerServer) WSHandler(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		// Answers to prompts carry a type; anything else is a command
+		var envelope api.ClientMessage
+		if err := json.Unmarshal(message, &envelope); err != nil {
+			errMsg := "Invalid JSON"
+			conn.WriteMessage(websocket.TextMessage, []byte(errMsg))
+			continue
+		}
+		if envelope.Type == api.MessageTypeRollResult || envelope.Type == api.MessageTypeReactionResponse {
+			if err := cs.prompts.resolve(envelope.RequestID, conn, message); err != nil {
+				conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+			}
+			continue
+		}
+		if envelope.Type == api.MessageTypeControl {
+			var control api.ControlMessage
+			if err := json.Unmarshal(message, &control); err != nil {
+				conn.WriteMessage(websocket.TextMessage, []byte("Invalid JSON"))
+				continue
+			}
+			if err := cs.controllers.claim(conn, control.EntityID); err != nil {
+				conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+				continue
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte("Entity controlled successfully"))
+			continue
+		}
+
 		var command CommandRequest
 		if err := json.Unmarshal(message, &command); err != nil {
 			errMsg := "Invalid JSON"
@@ -52,6 +80,11 @@ func (cs *ControllerServer) WSHandler(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		// Commanding an entity takes control of it, so its prompts come here
+		if err := cs.controllers.claim(conn, command.EntityID); err != nil {
+			conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+			continue
+		}
 		err = cs.Controller.AddActionWithCard(command.EntityID, command.ActionCardId, command.Params)
 		if err != nil {
 			conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
@@ -64,19 +97,32 @@ func (cs *ControllerServer) WSHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // broadcastUpdates listens on wsBroadcast and sends incoming messages to all connected WebSocket clients.
+// It also sends the messages on wsDirect to their single client, so that only it writes to the connections.
 func (cs *ControllerServer) broadcastUpdates() {
 	for {
-		msg := <-cs.wsBroadcast
-		for client := range cs.wsClients {
-			if err := client.WriteMessage(websocket.TextMessage, msg); err != nil {
-				fmt.Printf("WebSocket write error: %v\n", err)
-				client.Close()
-				delete(cs.wsClients, client)
+		select {
+		case msg := <-cs.wsBroadcast:
+			for client := range cs.wsClients {
+				cs.write(client, msg)
+			}
+		case direct := <-cs.wsDirect:
+			if cs.wsClients[direct.conn] {
+				cs.write(direct.conn, direct.data)
 			}
 		}
 	}
 }
 
+// write sends a message to a WebSocket client, dropping the client if it fails
+func (cs *ControllerServer) write(client *websocket.Conn, msg []byte) {
+	if err := client.WriteMessage(websocket.TextMessage, msg); err != nil {
+		fmt.Printf("WebSocket write error: %v\n", err)
+		client.Close()
+		delete(cs.wsClients, client)
+		cs.controllers.release(client)
+	}
+}
+
 // BroadcastGameStep sends a game step to all WebSocket clients in a frontend-friendly format
 func (cs *ControllerServer) BroadcastGameStep(step interface{}, message string) {
 	// First, check if the step implements the game.Step interface
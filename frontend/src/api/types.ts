@@ -12,6 +12,14 @@ export interface ActionCardRef {
   description?: string;
   actionCost: number;
   type?: string;
+  traits?: string[];
+  targets?: TargetRules; // Set for cards that take "targetIDs" rather than a "targetID"
+}
+
+export interface TargetRules {
+  maxTargets?: number; // Absent for no limit
+  distinct: boolean;
+  range?: number; // In feet; absent for no limit
 }
 
 export interface EntityState {
@@ -19,12 +27,76 @@ export interface EntityState {
   name: string;
   hp: number;
   maxHp: number;
+  tempHp?: number;
   ac: number;
+  armor?: string;
+  speed: number; // Land Speed in feet after armor
+  shield?: ShieldState;
+  spellcasting?: SpellcastingState;
   actionsRemaining: number;
   reactionsRemaining: number;
   faction: string;
   actionCards?: ActionCardRef[];
   position?: [number, number];
+  saves?: Record<string, number>;
+  skills?: Record<string, number>;
+  perception: number;
+  level: number;
+  statistics?: Record<string, StatisticBreakdown>;
+  conditions?: Condition[];
+  state: 'CONSCIOUS' | 'UNCONSCIOUS' | 'DYING' | 'DEAD';
+  resistances?: Record<string, number>;
+  weaknesses?: Record<string, number>;
+  immunities?: string[];
+}
+
+export interface ShieldState {
+  name: string;
+  acBonus: number;
+  hardness: number;
+  hp: number;
+  maxHp: number;
+  brokenThreshold: number;
+  raised: boolean;
+  broken: boolean;
+}
+
+export interface SpellcastingState {
+  tradition: string;
+  type: 'PREPARED' | 'SPONTANEOUS';
+  spellAttack: number;
+  spellDc: number;
+  slots?: Record<number, number>; // By rank: left to cast for spontaneous casters, in total for prepared ones
+  prepared?: PreparedSpell[];
+  focusPoints?: number;
+  maxFocusPoints?: number;
+}
+
+export interface PreparedSpell {
+  spell: string;
+  rank: number;
+  expended?: boolean;
+}
+
+export interface Condition {
+  name: string;
+  value?: number;
+  duration?: number;
+  source?: string;
+  damage?: string;
+}
+
+export interface PersistentDamageEventData {
+  entity: EntityRef;
+  damage: string;
+  type: string;
+  source?: string;
+}
+
+export interface StatisticBreakdown {
+  base?: number;
+  modifiers?: Modifier[];
+  total: number;
 }
 
 export interface GameState {
@@ -32,7 +104,39 @@ export interface GameState {
   currentTurn?: string;
   gridWidth: number;
   gridHeight: number;
+  walls?: [number, number][]; // Solid squares, which block movement and line of effect
   round: number;
+  seed: string;
+}
+
+export interface DieResult {
+  sides: number;
+  value: number;
+  dropped?: boolean;
+}
+
+export interface Modifier {
+  source?: string;
+  type?: string;
+  value: number;
+}
+
+export interface RollRecord {
+  expression: string;
+  dice: DieResult[];
+  modifiers?: Modifier[];
+  total: number;
+  summary: string;
+  fortune?: string;
+  discarded?: RollRecord[];
+  adjustments?: DamageAdjustment[];
+}
+
+export interface DamageAdjustment {
+  type: string;
+  kind: 'IMMUNITY' | 'WEAKNESS' | 'RESISTANCE';
+  against: string;
+  value: number;
 }
 
 export interface AttackEventData {
@@ -41,6 +145,7 @@ export interface AttackEventData {
   roll?: number;
   result?: number;
   degree?: string;
+  record?: RollRecord;
 }
 
 export interface DamageEventData {
@@ -50,12 +155,112 @@ export interface DamageEventData {
   type?: string;
   blocked?: number;
   taken?: number;
+  rolls?: RollRecord[];
+  fortune?: string;
+  discarded?: RollRecord[];
+  adjustments?: DamageAdjustment[];
+}
+
+export interface DamageAdjustment {
+  type: string;
+  kind: 'IMMUNITY' | 'WEAKNESS' | 'RESISTANCE';
+  against: string;
+  value: number;
+}
+
+export interface SaveEventData {
+  entity: EntityRef;
+  source?: EntityRef;
+  save: string;
+  dc: number;
+  roll?: number;
+  result?: number;
+  degree?: string;
+  record?: RollRecord;
+}
+
+export interface HealEventData {
+  source?: EntityRef;
+  target: EntityRef;
+  amount: number;
+  healed?: number;
+  rolls?: RollRecord[];
+}
+
+export interface CheckEventData {
+  roller: EntityRef;
+  target?: EntityRef;
+  check: string;
+  dc: number;
+  roll?: number;
+  result?: number;
+  degree?: string;
+  record?: RollRecord;
+}
+
+export interface RollRequestData {
+  requestId: string;
+  entity: EntityRef;
+  sides: number;
+  error?: string;
+}
+
+// Takes control of an entity, so that its roll and reaction prompts are sent
+// to this client alone and only this client can answer them
+export interface ControlMessage {
+  type: "CONTROL";
+  entity_id: string;
+}
+
+export interface RollResultMessage {
+  type: "ROLL_RESULT";
+  request_id: string;
+  value: number;
+}
+
+export interface ReactionPromptData {
+  requestId: string;
+  entity: EntityRef;
+  trigger: string;
+  options: string[];
+  timeout?: number; // Seconds before the reaction is declined
+}
+
+export interface ReactionResponseMessage {
+  type: "REACTION_RESPONSE";
+  request_id: string;
+  use: boolean;
+  choice?: number; // Index into the prompt's options
 }
 
 export interface TurnEventData {
   entity: EntityRef;
 }
 
+export interface ActionEventData {
+  entity: EntityRef;
+  action: string;
+  traits?: string[];
+  disrupted?: boolean;
+}
+
+export interface MoveEventData {
+  entity: EntityRef;
+  from: [number, number];
+  to: [number, number];
+  action?: string; // The move action, e.g. "Stride"
+}
+
+export interface AreaEventData {
+  source?: EntityRef;
+  shape: "BURST" | "CONE" | "LINE" | "EMANATION";
+  size: number; // In feet
+  origin: [number, number];
+  direction?: string; // Which way a cone or line points, e.g. "NE"
+  squares: [number, number][];
+  targets: EntityRef[];
+}
+
 export interface EventBase {
   type: string;
   version: string;
@@ -71,7 +276,30 @@ export interface GameEvent<T = any> extends EventBase {
 export interface CommandRequest {
   entity_id: string;
   action_card_id: string;
-  params: Record<string, any>;
+  params: Record<string, any>; // e.g. targetID, targetIDs, or position as [x, y]
+}
+
+export interface OddsRequest {
+  bonus: number;
+  damage: string;
+  ac: number;
+  fortune?: "fortune" | "misfortune";
+}
+
+export interface AttackOdds {
+  map: number;
+  bonus: number;
+  criticalSuccess: number;
+  success: number;
+  failure: number;
+  criticalFailure: number;
+  hitChance: number;
+  expectedDamage: number;
+}
+
+export interface OddsResponse {
+  ac: number;
+  attacks: AttackOdds[];
 }
 
 export interface CommandResponse {
@@ -95,5 +323,16 @@ export enum EventType {
   ROUND_END = "ROUND_END",
   ENTITY_MOVE = "ENTITY_MOVE",
   ENTITY_STATUS = "ENTITY_STATUS",
-  ACTION_COMPLETE = "ACTION_COMPLETE"
+  ACTION_START = "ACTION_START",
+  ACTION_COMPLETE = "ACTION_COMPLETE",
+  ROLL_REQUEST = "ROLL_REQUEST",
+  REACTION_PROMPT = "REACTION_PROMPT",
+  SAVE = "SAVE",
+  SAVE_RESULT = "SAVE_RESULT",
+  CHECK = "CHECK",
+  CHECK_RESULT = "CHECK_RESULT",
+  HEAL = "HEAL",
+  HEAL_RESULT = "HEAL_RESULT",
+  PERSISTENT_DAMAGE = "PERSISTENT_DAMAGE",
+  AREA = "AREA"
 }
\ No newline at end of file
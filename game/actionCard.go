@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	dice "pf2eEngine/util"
 )
 
 type ActionCardType string
@@ -19,6 +20,7 @@ const (
 const (
 	ActionCost = "action_cost"
 	TargetID   = "targetID"
+	TargetIDs  = "targetIDs"
 )
 
 func (a ActionCardType) ToCost(params map[string]interface{}) int {
@@ -30,7 +32,8 @@ func (a ActionCardType) ToCost(params map[string]interface{}) int {
 	case ThreeActionCard:
 		return 3
 	case VariableActionCard:
-		return params[ActionCost].(int)
+		cost, _ := variableCost(params, 0, 3)
+		return cost
 	case FreeActionCard:
 		return 0
 	default:
@@ -38,28 +41,64 @@ func (a ActionCardType) ToCost(params map[string]interface{}) int {
 	}
 }
 
+// variableCost reads the number of actions chosen for a variable action card.
+// Costs decoded from JSON arrive as float64.
+func variableCost(params map[string]interface{}, minCost, maxCost int) (int, error) {
+	var cost int
+	switch v := params[ActionCost].(type) {
+	case int:
+		cost = v
+	case float64:
+		cost = int(v)
+		if float64(cost) != v {
+			return 0, fmt.Errorf("action cost must be a whole number, got %v", v)
+		}
+	default:
+		return 0, errors.New("action_cost not found in params")
+	}
+	if cost < minCost || cost > maxCost {
+		return 0, fmt.Errorf("action cost must be between %d and %d, got %d", minCost, maxCost, cost)
+	}
+	return cost, nil
+}
+
 type ActionCard struct {
 	ID              uuid.UUID
 	Name            string
 	Type            ActionCardType
 	Description     string
+	Traits          []ActionTrait  // Given to the actions generated from the card
+	Targets         *TargetRules   // Set for cards that take "targetIDs" rather than a "targetID"
+	weapon          *WieldedWeapon // Set for Strike and Reload cards, which are replaced when the entity's weapons change
+	spell           *Spell         // Set for Cast a Spell cards, which are replaced when the entity's spells change
 	actionGenerator func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error)
 }
 
 func (ac ActionCard) GenerateAction(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+	action, err := ac.generate(gs, actor, params)
+	if err != nil {
+		gs.Printf("Failed to generate action: %v\n Params: %v\n", err, params)
+		return Action{}, err
+	}
+	return action, nil
+}
+
+// generate creates the card's action without logging failures, giving it the card's traits
+func (ac ActionCard) generate(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
 	action, err := ac.actionGenerator(gs, actor, params)
 	if err != nil {
-		fmt.Printf("Failed to generate action: %v\n Params: %v\n", err, params)
 		return Action{}, err
 	}
+	if action.Traits == nil {
+		action.Traits = ac.Traits
+	}
 	return action, nil
 }
 
 func getSingleTarget(gs *GameState, actor *Entity, criteria []TargetCriterion, params map[string]interface{}) (*Entity, error) {
-	targetID := params[TargetID].(uuid.UUID)
-	target := findEntityByID(gs.Initiative, targetID)
-	if target == nil {
-		return nil, errors.New("target not found")
+	target, err := getTarget(gs, params)
+	if err != nil {
+		return nil, err
 	}
 	for _, criterion := range criteria {
 		if err := criterion(gs, actor, params); err != nil {
@@ -70,9 +109,9 @@ func getSingleTarget(gs *GameState, actor *Entity, criteria []TargetCriterion, p
 }
 
 func getTarget(gs *GameState, params map[string]interface{}) (*Entity, error) {
-	targetID, err := params[TargetID].(uuid.UUID)
-	if !err {
-		return nil, errors.New("targetID not found in params")
+	targetID, err := targetIDParam(params)
+	if err != nil {
+		return nil, err
 	}
 	target := findEntityByID(gs.Initiative, targetID)
 	if target == nil {
@@ -99,10 +138,9 @@ func Range(maxDistance int) TargetCriterion {
 
 func IsAlive() TargetCriterion {
 	return func(gs *GameState, actor *Entity, params map[string]interface{}) error {
-		targetId, ok := params[TargetID].(uuid.UUID)
-		target := findEntityByID(gs.Initiative, targetId)
-		if !ok {
-			return errors.New("target not found")
+		target, err := getTarget(gs, params)
+		if err != nil {
+			return err
 		}
 		if !target.IsAlive() {
 			return errors.New("target is not alive")
@@ -111,6 +149,21 @@ func IsAlive() TargetCriterion {
 	}
 }
 
+// InLineOfEffect requires an unbroken line from the actor to the target.
+// Walls block line of effect; creatures don't.
+func InLineOfEffect() TargetCriterion {
+	return func(gs *GameState, actor *Entity, params map[string]interface{}) error {
+		target, err := getTarget(gs, params)
+		if err != nil {
+			return err
+		}
+		if !gs.Grid.HasLineOfEffect(gs.Grid.GetEntityPosition(actor), gs.Grid.GetEntityPosition(target)) {
+			return errors.New("target is not in line of effect")
+		}
+		return nil
+	}
+}
+
 func NewSingleTargetActionCard(
 	name string,
 	actionType ActionCardType,
@@ -139,37 +192,153 @@ func NewSingleTargetActionCard(
 	}
 }
 
-func NewStrikeCard(attack BaseAttack) *ActionCard {
-	return NewSingleTargetActionCard(
-		"Strike",
-		OneActionCard,
-		"Make a melee strike against a target within reach.",
-		[]TargetCriterion{
-			IsAlive(),
-			Range(5),
-		},
-		func(gs *GameState, actor *Entity, target *Entity) {
-			PerformAttack(gs, attack, actor, target)
+// TargetRules limit the targets chosen for a multi-target card
+type TargetRules struct {
+	MaxTargets int  // Most targets that can be chosen; zero for no limit
+	Distinct   bool // Each creature can be chosen only once
+	Range      int  // Feet from the actor to each target; zero for no limit
+}
+
+// targetIDs reads the IDs chosen for a multi-target card. IDs decoded from
+// JSON arrive as strings.
+func targetIDs(params map[string]interface{}) ([]uuid.UUID, error) {
+	var values []interface{}
+	switch v := params[TargetIDs].(type) {
+	case []uuid.UUID:
+		return v, nil
+	case []string:
+		for _, s := range v {
+			values = append(values, s)
+		}
+	case []interface{}:
+		values = v
+	case nil:
+		return nil, errors.New("targetIDs not found in params")
+	default:
+		return nil, errors.New("targetIDs must be a list of entity IDs")
+	}
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		switch id := value.(type) {
+		case uuid.UUID:
+			ids = append(ids, id)
+		case string:
+			parsed, err := uuid.Parse(id)
+			if err != nil {
+				return nil, fmt.Errorf("invalid target ID %q", id)
+			}
+			ids = append(ids, parsed)
+		default:
+			return nil, errors.New("targetIDs must be a list of entity IDs")
+		}
+	}
+	return ids, nil
+}
+
+// getTargets finds the targets chosen for a multi-target card, checking them
+// against the card's rules and every criterion in turn
+func getTargets(gs *GameState, actor *Entity, rules TargetRules, criteria []TargetCriterion, params map[string]interface{}) ([]*Entity, error) {
+	ids, err := targetIDs(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("no targets chosen")
+	}
+	if rules.MaxTargets > 0 && len(ids) > rules.MaxTargets {
+		return nil, fmt.Errorf("too many targets (max: %d)", rules.MaxTargets)
+	}
+	if rules.Range > 0 {
+		criteria = append([]TargetCriterion{Range(rules.Range)}, criteria...)
+	}
+	criteria = append(criteria, InLineOfEffect())
+
+	targets := make([]*Entity, 0, len(ids))
+	chosen := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		if rules.Distinct && chosen[id] {
+			return nil, errors.New("the same target can't be chosen twice")
+		}
+		chosen[id] = true
+		target := findEntityByID(gs.Initiative, id)
+		if target == nil {
+			return nil, errors.New("target not found")
+		}
+		// Criteria read the target from params, so check each with its own
+		targetParams := make(map[string]interface{}, len(params)+1)
+		for k, v := range params {
+			targetParams[k] = v
+		}
+		targetParams[TargetID] = id
+		for _, criterion := range criteria {
+			if err := criterion(gs, actor, targetParams); err != nil {
+				return nil, fmt.Errorf("%s: %w", target.Name, err)
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// NewMultiTargetActionCard creates an action card that takes a list of
+// "targetIDs" rather than a single target. actionFunc is called for each
+// target in the order chosen, so each gets its own rolls; targets that died
+// to an earlier one are skipped.
+func NewMultiTargetActionCard(
+	name string,
+	actionType ActionCardType,
+	description string,
+	rules TargetRules,
+	criteria []TargetCriterion,
+	actionFunc func(gs *GameState, actor *Entity, target *Entity),
+) *ActionCard {
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        name,
+		Type:        actionType,
+		Description: description,
+		Targets:     &rules,
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			targets, err := getTargets(gs, actor, rules, criteria, params)
+			if err != nil {
+				return Action{}, err
+			}
+			return Action{
+				Name: name,
+				Cost: actionType.ToCost(params),
+				perform: func(gs *GameState, actor *Entity) {
+					for _, target := range targets {
+						if target.IsAlive() {
+							actionFunc(gs, actor, target)
+						}
+					}
+				},
+			}, nil
 		},
-	)
+	}
 }
 
-// NewStrideCard creates a movement action card according to PF2E rules.
+// NewStrideCard creates a movement action card according to PF2E rules. A
+// "position" param picks the square to Stride to; otherwise the actor
+// Strides towards the entity chosen with "targetID".
 func NewStrideCard() *ActionCard {
 	return &ActionCard{
 		ID:          uuid.New(),
 		Name:        "Stride",
 		Type:        OneActionCard,
-		Description: "Move up to your Speed (default: 5 squares).",
+		Description: "Move up to your Speed (default: 25 feet).",
+		Traits:      []ActionTrait{MoveTrait},
 		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
-			targetID, ok := params["targetID"].(uuid.UUID)
-			if !ok {
-				return Action{}, errors.New("target not found in params")
+			if err := canMove(actor); err != nil {
+				return Action{}, err
 			}
-			
-			target := findEntityByID(gs.Initiative, targetID)
-			if target == nil {
-				return Action{}, errors.New("target entity does not exist")
+			if _, ok := params[PositionParam]; ok {
+				return strideToSquare(gs, actor, params)
+			}
+
+			target, err := getTarget(gs, params)
+			if err != nil {
+				return Action{}, err
 			}
 			
 			return Action{
@@ -179,26 +348,123 @@ func NewStrideCard() *ActionCard {
 					actorPos := gs.Grid.GetEntityPosition(actor)
 					targetPos := gs.Grid.GetEntityPosition(target)
 					
-					// Default movement speed in PF2E is 25 feet (5 squares)
-					speed := 5
+					// Speed is in feet and each square is 5 feet
+					speed := actor.LandSpeed() / 5
 					
 					// Find the best position to move to
 					newPos := gs.Grid.FindBestMove(actorPos, targetPos, speed)
 					
-					// If we found a valid position to move to
+					// Move there a square at a time so reactions can interrupt the Stride
 					if newPos != actorPos {
-						success := gs.Grid.MoveEntity(actorPos, newPos)
-						if success {
-							fmt.Printf("%s strides from (%d,%d) to (%d,%d).\n", 
-								actor.Name, actorPos.X, actorPos.Y, newPos.X, newPos.Y)
+						path := pathWithin(actorPos, gs.Grid.Path(actorPos, newPos), actor.LandSpeed())
+						endPos := MoveAlong(gs, actor, path, "Stride")
+						if endPos != actorPos {
+							gs.Printf("%s strides from (%d,%d) to (%d,%d).\n",
+								actor.Name, actorPos.X, actorPos.Y, endPos.X, endPos.Y)
 						} else {
-							fmt.Printf("%s attempted to stride but was blocked.\n", actor.Name)
+							gs.Printf("%s attempted to stride but was blocked.\n", actor.Name)
 						}
 					} else {
-						fmt.Printf("%s cannot stride any closer to %s.\n", actor.Name, target.Name)
+						gs.Printf("%s cannot stride any closer to %s.\n", actor.Name, target.Name)
 					}
 				},
 			}, nil
 		},
 	}
 }
+
+// NewDemoralizeCard creates an Intimidation check against a target's Will DC
+// that leaves it frightened
+func NewDemoralizeCard() *ActionCard {
+	card := NewSingleTargetActionCard(
+		"Demoralize",
+		OneActionCard,
+		"Attempt an Intimidation check against the Will DC of a creature within 30 feet to frighten it.",
+		[]TargetCriterion{
+			IsAlive(),
+			Range(30),
+			InLineOfEffect(),
+		},
+		func(gs *GameState, actor *Entity, target *Entity) {
+			check := CheckAgainst(gs, actor, Statistic(Intimidation), target, Statistic(Will))
+			frightened := 0
+			switch check.Degree {
+			case CriticalSuccess:
+				frightened = 2
+			case Success:
+				frightened = 1
+			}
+			if frightened > 0 {
+				target.AddCondition(Condition{Name: Frightened, Value: frightened, Source: "Demoralize"})
+				gs.Printf("%s is now %s.\n", target.Name, Condition{Name: Frightened, Value: frightened})
+			}
+		},
+	)
+	card.Traits = []ActionTrait{AuditoryTrait, ConcentrateTrait, EmotionTrait, MentalTrait}
+	return card
+}
+
+// NewTripCard creates an Athletics check against a target's Reflex DC that
+// knocks it prone
+func NewTripCard() *ActionCard {
+	card := NewSingleTargetActionCard(
+		"Trip",
+		OneActionCard,
+		"Attempt an Athletics check against the Reflex DC of an adjacent creature to knock it prone.",
+		[]TargetCriterion{
+			IsAlive(),
+			Range(5),
+		},
+		func(gs *GameState, actor *Entity, target *Entity) {
+			// Trip has the attack trait, so the multiple attack penalty applies
+			modifiers := append(actor.breakdown(Statistic(Athletics), true).DiceModifiers(),
+				dice.Modifier{Source: "MAP", Value: multipleAttackPenalty(actor.MapCounter)})
+			check := ResolveCheck(gs, &Check{
+				Roller:    actor,
+				Target:    target,
+				Name:      statisticName(Statistic(Athletics)),
+				Modifiers: modifiers,
+				DC:        target.DC(Statistic(Reflex)),
+			})
+			switch check.Degree {
+			case CriticalSuccess:
+				target.AddCondition(Condition{Name: Prone, Source: "Trip"})
+				gs.Printf("%s falls prone.\n", target.Name)
+				Deal(gs, rollDamage(gs, actor, target, MustParseDamage("1d6 bludgeoning")))
+			case Success:
+				target.AddCondition(Condition{Name: Prone, Source: "Trip"})
+				gs.Printf("%s falls prone.\n", target.Name)
+			case CriticalFailure:
+				actor.AddCondition(Condition{Name: Prone, Source: "Trip"})
+				gs.Printf("%s loses their balance and falls prone.\n", actor.Name)
+			}
+			gs.recordAttack(actor, target, nil)
+		},
+	)
+	card.Traits = []ActionTrait{AttackTrait}
+	return card
+}
+
+// NewStandCard creates an action that ends the prone condition
+func NewStandCard() *ActionCard {
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        "Stand",
+		Type:        OneActionCard,
+		Description: "Stand up from prone.",
+		Traits:      []ActionTrait{MoveTrait},
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			if _, ok := actor.Condition(Prone); !ok {
+				return Action{}, errors.New("actor is not prone")
+			}
+			return Action{
+				Name: "Stand",
+				Cost: 1,
+				perform: func(gs *GameState, actor *Entity) {
+					actor.RemoveCondition(Prone)
+					gs.Printf("%s stands up.\n", actor.Name)
+				},
+			}, nil
+		},
+	}
+}
@@ -3,6 +3,7 @@ package game
 import (
 	"fmt"
 	dice "pf2eEngine/util"
+	"strings"
 )
 
 type Attack struct {
@@ -12,6 +13,8 @@ type Attack struct {
 	Bonus    int
 	Result   int
 	Degree   DegreeOfSuccess
+	Record   dice.RollRecord
+	Weapon   *Weapon // The weapon used for a Strike; nil for other attacks
 }
 
 type BeforeAttackStep struct {
@@ -31,6 +34,9 @@ func NewBeforeAttackStep(attack *Attack) BeforeAttackStep {
 			metadata: map[string]interface{}{
 				"Attacker": attack.Attacker.Name,
 				"Defender": attack.Defender.Name,
+				"Weapon":   weaponName(attack.Weapon),
+				"Roll":     attack.Record.String(),
+				"Rolls":    d20Faces(attack.Record),
 			},
 		},
 		Attack: attack,
@@ -52,6 +58,15 @@ func NewAfterAttackStep(attack *Attack) AfterAttackStep {
 	}
 }
 
+// d20Faces lists every d20 rolled for the check, kept result first
+func d20Faces(record dice.RollRecord) []int {
+	faces := []int{d20Face(record)}
+	for _, d := range record.Discarded {
+		faces = append(faces, d20Face(d))
+	}
+	return faces
+}
+
 // ActionType defines the category of an action
 type ActionType string
 
@@ -63,26 +78,66 @@ const (
 	EndOfTurn    ActionType = "END_OF_TURN"
 )
 
+// ActionTrait is a trait of an action that rules and reactions key off, e.g.
+// Reactive Strike triggering on manipulate actions
+type ActionTrait string
+
+const (
+	AttackTrait      ActionTrait = "ATTACK"
+	AuditoryTrait    ActionTrait = "AUDITORY"
+	ConcentrateTrait ActionTrait = "CONCENTRATE"
+	EmotionTrait     ActionTrait = "EMOTION"
+	ManipulateTrait  ActionTrait = "MANIPULATE"
+	MentalTrait      ActionTrait = "MENTAL"
+	MoveTrait        ActionTrait = "MOVE"
+)
+
 type Action struct {
 	Name        string
 	Type        ActionType
 	Cost        int
 	Description string
+	Traits      []ActionTrait
+	Disrupted   bool                               // Set by a reaction to stop the action taking effect, e.g. a critical Reactive Strike
+	pay         func(gs *GameState, actor *Entity) // Spends costs beyond actions, e.g. a spell slot, which are lost even if the action is disrupted
 	perform     func(gs *GameState, actor *Entity)
 }
 
+// HasTrait reports whether the action has the trait
+func (a Action) HasTrait(trait ActionTrait) bool {
+	for _, t := range a.Traits {
+		if t == trait {
+			return true
+		}
+	}
+	return false
+}
+
+// StartActionStep is taken once an action's cost is paid, before it takes
+// effect. Reactions can disrupt the action through the step.
 type StartActionStep struct {
 	BaseStep
-	Action Action
+	Action *Action
 	Actor  *Entity
 }
 
 type EndActionStep struct {
 	BaseStep
-	Action Action
+	Action *Action
 	Actor  *Entity
 }
 
+// actionMetadata describes an action taken by an entity for the step log
+func actionMetadata(actor *Entity, action *Action) map[string]interface{} {
+	return map[string]interface{}{
+		"entity_id":   actor.Id.String(),
+		"entity_name": actor.Name,
+		"action":      action.Name,
+		"traits":      action.Traits,
+		"disrupted":   action.Disrupted,
+	}
+}
+
 func EndTurnAction(gs *GameState, actor *Entity) Action {
 	return Action{
 		Name:    "End Turn",
@@ -92,27 +147,42 @@ func EndTurnAction(gs *GameState, actor *Entity) Action {
 }
 
 func ExecuteAction(gs *GameState, actor *Entity, action Action) {
+	if !actor.IsConscious() {
+		gs.Printf("%s cannot act while %s.\n", actor.Name, strings.ToLower(string(actor.LifeState())))
+		return
+	}
 	if actor.ActionsRemaining < action.Cost {
-		fmt.Printf("%s does not have enough actions to perform %s. Actions remaining: %d, action cost: %d.\n",
+		gs.Printf("%s does not have enough actions to perform %s. Actions remaining: %d, action cost: %d.\n",
 			actor.Name, action.Name, actor.ActionsRemaining, action.Cost)
 		return
 	}
 
 	actor.SpendAction(action.Cost)
-	fmt.Printf("%s used %d actions. Actions remaining: %d.\n",
+	if action.pay != nil {
+		action.pay(gs, actor)
+	}
+	gs.Printf("%s used %d actions. Actions remaining: %d.\n",
 		actor.Name, action.Cost, actor.ActionsRemaining)
 
 	executeStep(gs, StartActionStep{
-		BaseStep: BaseStep{StepType: StartTurn},
-		Action:   action,
+		BaseStep: BaseStep{StepType: StartAction, metadata: actionMetadata(actor, &action)},
+		Action:   &action,
 		Actor:    actor,
 	}, fmt.Sprintf("%s starts the action: %s.", actor.Name, action.Name))
 
-	action.perform(gs, actor)
+	// Reactions to the start of the action can disrupt it or take the actor down
+	switch {
+	case action.Disrupted:
+		gs.Printf("%s's %s is disrupted.\n", actor.Name, action.Name)
+	case !actor.IsConscious():
+		gs.Printf("%s is unable to finish %s.\n", actor.Name, action.Name)
+	default:
+		action.perform(gs, actor)
+	}
 
 	executeStep(gs, EndActionStep{
-		BaseStep: BaseStep{StepType: EndTurn},
-		Action:   action,
+		BaseStep: BaseStep{StepType: EndAction, metadata: actionMetadata(actor, &action)},
+		Action:   &action,
 		Actor:    actor,
 	}, fmt.Sprintf("%s completed the action: %s.", actor.Name, action.Name))
 }
@@ -124,36 +194,52 @@ func PerformAttack(gs *GameState, baseAttack BaseAttack, attacker *Entity, defen
 
 	// Check if attacker and defender are adjacent (required for melee attacks)
 	if !gs.Grid.AreAdjacent(attackerPos, defenderPos) {
-		fmt.Printf("%s cannot attack %s; they are not adjacent (distance: %d).\n", 
+		gs.Printf("%s cannot attack %s; they are not adjacent (distance: %d).\n",
 			attacker.Name, defender.Name, gs.Grid.CalculateDistance(attackerPos, defenderPos))
 		return
 	}
 
-	roll := dice.Roll(20)
+	modifiers := append(attacker.AttackBreakdown(baseAttack).DiceModifiers(),
+		dice.Modifier{Source: "MAP", Value: multipleAttackPenalty(attacker.MapCounter)})
+	resolveAttack(gs, attacker, defender, nil, modifiers, func(critical bool) Damage {
+		damage := rollAttackDamage(gs, baseAttack, attacker, defender)
+		if critical {
+			damage = damage.Double()
+		}
+		return damage
+	})
+}
+
+// resolveAttack rolls an attack with the given modifiers against the
+// defender's AC and deals the damage rolled by damage on a hit. Callers check
+// that the defender is within reach or range.
+func resolveAttack(gs *GameState, attacker *Entity, defender *Entity, weapon *Weapon, modifiers []dice.Modifier, damage func(critical bool) Damage) *Attack {
+	record := rollD20(gs, attacker, modifiers)
+	roll := d20Face(record)
 	attack := &Attack{
 		Attacker: attacker,
 		Defender: defender,
 		Roll:     roll,
-		Bonus:    baseAttack.Bonus - attacker.MapCounter*5,
-		Result:   roll + baseAttack.Bonus - attacker.MapCounter*5,
+		Bonus:    record.Total - roll,
+		Result:   record.Total,
+		Record:   record,
+		Weapon:   weapon,
 	}
-	attack.Degree = calculateDegreeOfSuccess(roll, attack.Result, defender.AC)
+	ac := defender.ArmorClass()
+	attack.Degree = calculateDegreeOfSuccess(roll, attack.Result, ac)
 
 	details := fmt.Sprintf(
-		"Attack Details:\n\tAttacker: %s\n\tDefender: %s\n\tRoll: %d\n\tBonus: %d\n\tResult: %d\n\tDefender AC: %d\n\tDegree: %v",
-		attacker.Name, defender.Name, roll, attack.Bonus, attack.Result, defender.AC, attack.Degree.String(),
+		"Attack Details:\n\tAttacker: %s\n\tDefender: %s\n\tRoll: %s vs AC %d\n\tDegree: %v",
+		attacker.Name, defender.Name, record.String(), ac, attack.Degree.String(),
 	)
 
-	damageRoll := baseAttack.RollDamage()
-
-	damage := Damage{Source: attacker, Target: defender, Amount: damageRoll}
 	switch attack.Degree {
 	case CriticalSuccess:
 		executeStep(gs, NewBeforeAttackStep(attack), fmt.Sprintf("%s has critically hit %s! Details:\n%s", attacker.Name, defender.Name, details))
-		Deal(gs, damage.Double())
+		Deal(gs, damage(true))
 	case Success:
 		executeStep(gs, NewBeforeAttackStep(attack), fmt.Sprintf("%s has hit %s. Details:\n%s", attacker.Name, defender.Name, details))
-		Deal(gs, damage)
+		Deal(gs, damage(false))
 	case Failure:
 		executeStep(gs, NewBeforeAttackStep(attack), fmt.Sprintf("%s has missed %s. Details:\n%s", attacker.Name, defender.Name, details))
 	case CriticalFailure:
@@ -161,5 +247,19 @@ func PerformAttack(gs *GameState, baseAttack BaseAttack, attacker *Entity, defen
 	}
 
 	executeStep(gs, NewAfterAttackStep(attack), fmt.Sprintf("%s has finished attacking %s.", attacker.Name, defender.Name))
-	attacker.MapCounter++
+	gs.recordAttack(attacker, defender, weapon)
+	return attack
+}
+
+// weaponName returns the weapon's name, or an empty string for attacks without one
+func weaponName(w *Weapon) string {
+	if w == nil {
+		return ""
+	}
+	return w.Name
+}
+
+// rollAttackDamage rolls the damage of a hit, honouring the attacker's fortune effects
+func rollAttackDamage(gs *GameState, baseAttack BaseAttack, attacker *Entity, defender *Entity) Damage {
+	return rollDamage(gs, attacker, defender, baseAttack.Damage)
 }
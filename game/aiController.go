@@ -1,8 +1,9 @@
 package game
 
 import (
-	"fmt"
 	"math"
+	"sort"
+	"strings"
 )
 
 // AIController implements basic AI logic for entities
@@ -25,34 +26,73 @@ func (a AIController) NextAction(gs *GameState, e *Entity) Action {
 	// Prepare common parameters
 	params := map[string]interface{}{}
 	params["targetID"] = target.Id
+	params[OriginParam] = targetPos
+	params[DirectionParam] = string(DirectionTowards(actorPos, targetPos))
 	
-	// If not adjacent to target, try to stride towards them
+	// Get back up before doing anything else
+	if standCard := getActionCardByName(e, "Stand"); standCard != nil && e.HasCondition(Prone) {
+		action, err := standCard.GenerateAction(gs, e, params)
+		if err == nil {
+			return action
+		}
+	}
+	
+	// Keep the last action to raise a shield once in melee
+	if raiseCard := getActionCardByName(e, "Raise a Shield"); raiseCard != nil && e.ActionsRemaining == 1 &&
+		gs.Grid.AreAdjacent(actorPos, targetPos) {
+		action, err := raiseCard.GenerateAction(gs, e, params)
+		if err == nil {
+			return action
+		}
+	}
+	
+	// Heal a wounded ally, or else hit the target with a spell
+	if action, ok := chooseSpell(gs, e, params); ok {
+		return action
+	}
+	
+	// Strike with the first weapon that can reach the target
+	for _, strike := range getStrikeCards(e) {
+		// Probe without logging: Strikes that can't reach are expected to fail
+		action, err := strike.generate(gs, e, params)
+		if err == nil {
+			return action
+		}
+	}
+	
+	// Reload a weapon that needs it before striding
+	for _, card := range e.ActionCards {
+		if strings.HasPrefix(card.Name, "Reload") {
+			action, err := card.generate(gs, e, params)
+			if err == nil {
+				return action
+			}
+		}
+	}
+	
+	// If no Strike can reach the target, try to stride towards them
 	if !gs.Grid.AreAdjacent(actorPos, targetPos) {
 		// Look for a stride card
 		strideCard := getActionCardByName(e, "Stride")
 		if strideCard != nil {
 			action, err := strideCard.GenerateAction(gs, e, params)
 			if err == nil {
-				fmt.Printf("%s decides to stride towards %s.\n", e.Name, target.Name)
+				gs.Printf("%s decides to stride towards %s.\n", e.Name, target.Name)
 				return action
 			}
 		}
 	}
 	
-	// If adjacent to target or no stride card available, try to strike
-	strikes := getActionCardsByName(e, "Strike")
-	if len(strikes) > 0 {
-		action, err := strikes[0].GenerateAction(gs, e, params)
-		if err == nil {
-			return action
-		}
-	}
-	
 	// If no valid action could be generated, end turn
-	fmt.Printf("%s has no valid actions remaining.\n", e.Name)
+	gs.Printf("%s has no valid actions remaining.\n", e.Name)
 	return EndTurnAction(gs, e)
 }
 
+// ChooseReaction always takes the first reaction on offer
+func (a AIController) ChooseReaction(gs *GameState, e *Entity, req ReactionRequest) int {
+	return 0
+}
+
 func getActionCardByName(e *Entity, name string) *ActionCard {
 	for _, card := range e.ActionCards {
 		if card.Name == name {
@@ -72,18 +112,79 @@ func getActionCardsByName(e *Entity, name string) []*ActionCard {
 	return cards
 }
 
-func getStrikeCards(e *Entity) []*ActionCard {
-	return getActionCardsByName(e, "Strike")
+// chooseSpell picks a spell there are actions left for: a beneficial spell
+// on the most wounded ally it can reach, or else the first harmful spell that
+// can reach the target without catching the caster or its allies in its area
+func chooseSpell(gs *GameState, e *Entity, params map[string]interface{}) (Action, bool) {
+	for _, card := range e.ActionCards {
+		if card.spell == nil || !card.spell.Beneficial {
+			continue
+		}
+		for _, ally := range woundedAllies(gs, e) {
+			allyParams := map[string]interface{}{}
+			for k, v := range params {
+				allyParams[k] = v
+			}
+			allyParams[TargetID] = ally.Id
+			action, err := card.generate(gs, e, allyParams)
+			if err == nil && action.Cost <= e.ActionsRemaining {
+				return action, true
+			}
+		}
+	}
+	for _, card := range e.ActionCards {
+		if card.spell == nil || card.spell.Beneficial {
+			continue
+		}
+		if card.spell.Area != nil && catchesAlly(gs, e, *card.spell, params) {
+			continue
+		}
+		action, err := card.generate(gs, e, params)
+		if err == nil && action.Cost <= e.ActionsRemaining {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// woundedAllies lists the entity's living allies below their maximum HP,
+// including the entity itself, the most wounded first
+func woundedAllies(gs *GameState, e *Entity) []*Entity {
+	var wounded []*Entity
+	for _, other := range gs.Initiative {
+		if other.Faction == e.Faction && other.IsAlive() && other.HP < other.MaxHP {
+			wounded = append(wounded, other)
+		}
+	}
+	sort.SliceStable(wounded, func(i, j int) bool {
+		return wounded[i].HP*wounded[j].MaxHP < wounded[j].HP*wounded[i].MaxHP
+	})
+	return wounded
+}
+
+// catchesAlly reports whether an area spell aimed with params would cover
+// the caster or any of its allies
+func catchesAlly(gs *GameState, e *Entity, s Spell, params map[string]interface{}) bool {
+	template, err := areaTemplate(gs, e, *s.Area, s.Range, params)
+	if err != nil {
+		return false
+	}
+	for _, other := range gs.Grid.EntitiesIn(template) {
+		if other.Faction == e.Faction {
+			return true
+		}
+	}
+	return false
 }
 
-// findNearestEntity locates the closest living entity to the given entity
+// findNearestEnemy locates the closest conscious enemy of the given entity
 func findNearestEnemy(gs *GameState, e *Entity) *Entity {
 	currentPos := gs.Grid.GetEntityPosition(e)
 	var closest *Entity
 	minDistance := math.MaxInt
 
 	for _, other := range gs.Initiative {
-		if other == e || !other.IsAlive() || other.Faction == e.Faction {
+		if other == e || !other.IsConscious() || other.Faction == e.Faction {
 			continue
 		}
 		otherPos := gs.Grid.GetEntityPosition(other)
@@ -0,0 +1,77 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+	"testing"
+)
+
+func newCaster(name string, faction Faction, spells ...Spell) *Entity {
+	e := NewEntity(name, 20, 15, faction)
+	e.SetSpellcasting(Spellcasting{Tradition: Arcane, Type: SpontaneousCasting, Attack: 7, DC: 17,
+		Slots: map[int]int{1: 2}, FocusPoints: 1, MaxFocusPoints: 1})
+	for _, s := range spells {
+		if err := e.LearnSpell(s); err != nil {
+			panic(err)
+		}
+	}
+	return e
+}
+
+func TestAICastsBeneficialSpellsOnWoundedAllies(t *testing.T) {
+	healer := newCaster("Healer", GoodGuys, LayOnHands)
+	ally := NewEntity("Ally", 20, 15, GoodGuys)
+	enemy := NewEntity("Enemy", 20, 15, BadGuys)
+	gs := newTestGame([]Spawn{NewSpawn(healer, 0, 0), NewSpawn(ally, 0, 1), NewSpawn(enemy, 1, 0)}, dice.NewSeededSource(1))
+	ally.HP = 5
+	enemy.HP = 10
+
+	action := NewAIController().NextAction(gs, healer)
+	if action.Name != "Cast a Spell" {
+		t.Fatalf("healer chose %q, want to cast Lay on Hands", action.Name)
+	}
+	ExecuteAction(gs, healer, action)
+	if ally.HP != 11 {
+		t.Errorf("ally has %d HP, want 11", ally.HP)
+	}
+	if enemy.HP != 10 {
+		t.Errorf("enemy has %d HP, want it left alone on 10", enemy.HP)
+	}
+}
+
+func TestAIDoesNotHealEnemies(t *testing.T) {
+	healer := newCaster("Healer", GoodGuys, LayOnHands)
+	enemy := NewEntity("Enemy", 20, 15, BadGuys)
+	gs := newTestGame([]Spawn{NewSpawn(healer, 0, 0), NewSpawn(enemy, 1, 0)}, dice.NewSeededSource(1))
+	enemy.HP = 10
+
+	if action := NewAIController().NextAction(gs, healer); action.Name == "Cast a Spell" {
+		ExecuteAction(gs, healer, action)
+		t.Errorf("healer cast a spell with no wounded allies; enemy now has %d HP", enemy.HP)
+	}
+}
+
+func TestAIKeepsAlliesOutOfItsAreas(t *testing.T) {
+	tests := []struct {
+		name     string
+		allyAt   Position
+		wantCast bool
+	}{
+		{"ally in the cone", Position{X: 1, Y: 0}, false},
+		{"ally clear of the cone", Position{X: 0, Y: 4}, true},
+	}
+	for _, tt := range tests {
+		caster := newCaster("Caster", GoodGuys, BreatheFire)
+		ally := NewEntity("Ally", 20, 15, GoodGuys)
+		enemy := NewEntity("Enemy", 20, 15, BadGuys)
+		gs := newTestGame([]Spawn{
+			NewSpawn(caster, 0, 0),
+			NewSpawn(ally, tt.allyAt.X, tt.allyAt.Y),
+			NewSpawn(enemy, 2, 0),
+		}, dice.NewSeededSource(1))
+
+		action := NewAIController().NextAction(gs, caster)
+		if got := action.Name == "Cast a Spell"; got != tt.wantCast {
+			t.Errorf("%s: caster chose %q, want casting %v", tt.name, action.Name, tt.wantCast)
+		}
+	}
+}
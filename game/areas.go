@@ -0,0 +1,283 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// AreaShape is the shape of an area effect
+type AreaShape string
+
+const (
+	Burst     AreaShape = "BURST"     // Spreads from a grid corner in every direction
+	Cone      AreaShape = "CONE"      // Spreads from the origin's square in a quarter circle
+	Line      AreaShape = "LINE"      // Runs one square wide from the origin's square
+	Emanation AreaShape = "EMANATION" // Spreads from every side of the origin's square, including it
+)
+
+// Direction is one of the eight compass directions a cone or line points in.
+// North is towards the top of the grid, where Y is 0.
+type Direction string
+
+const (
+	North     Direction = "N"
+	NorthEast Direction = "NE"
+	East      Direction = "E"
+	SouthEast Direction = "SE"
+	South     Direction = "S"
+	SouthWest Direction = "SW"
+	West      Direction = "W"
+	NorthWest Direction = "NW"
+)
+
+// directionSteps is the square offset of a step in each direction
+var directionSteps = map[Direction]Position{
+	North:     {X: 0, Y: -1},
+	NorthEast: {X: 1, Y: -1},
+	East:      {X: 1, Y: 0},
+	SouthEast: {X: 1, Y: 1},
+	South:     {X: 0, Y: 1},
+	SouthWest: {X: -1, Y: 1},
+	West:      {X: -1, Y: 0},
+	NorthWest: {X: -1, Y: -1},
+}
+
+// ParseDirection reads a compass direction such as "NE", ignoring case
+func ParseDirection(name string) (Direction, error) {
+	d := Direction(strings.ToUpper(name))
+	if _, ok := directionSteps[d]; !ok {
+		return "", fmt.Errorf("unknown direction %q", name)
+	}
+	return d, nil
+}
+
+// DirectionTowards is the compass direction that points most nearly from one square to another
+func DirectionTowards(from, to Position) Direction {
+	step := Position{X: sign(to.X - from.X), Y: sign(to.Y - from.Y)}
+	// Only go diagonally when the target is closer to the diagonal than to the axis
+	dx, dy := abs(to.X-from.X), abs(to.Y-from.Y)
+	if dx > 2*dy {
+		step.Y = 0
+	} else if dy > 2*dx {
+		step.X = 0
+	}
+	for d, s := range directionSteps {
+		if s == step {
+			return d
+		}
+	}
+	return East
+}
+
+// sign returns -1, 0 or 1 for negative, zero and positive integers
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
+
+// Area is the shape and size of an area effect
+type Area struct {
+	Shape AreaShape
+	Size  int // Radius of bursts and emanations, or length of cones and lines, in feet
+}
+
+// String renders the area for logs, e.g. "20-foot burst"
+func (a Area) String() string {
+	return fmt.Sprintf("%d-foot %s", a.Size, strings.ToLower(string(a.Shape)))
+}
+
+// AreaTemplate is an area placed on the grid. A burst spreads from the
+// top-left corner of its origin square; the other shapes come from the
+// origin square itself, usually the square of whoever creates them.
+type AreaTemplate struct {
+	Area
+	Origin    Position
+	Direction Direction // Which way a cone or line points
+}
+
+// Squares lists the squares on the grid inside the template, measuring
+// distance with the PF2E diagonal rule that CalculateDistance uses. Walls
+// and squares they cut off from the origin are left out.
+func (g *Grid) Squares(t AreaTemplate) []Position {
+	var squares []Position
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			if p := (Position{X: x, Y: y}); g.inTemplate(t, p) && !g.IsWall(p) && g.HasLineOfEffect(t.Origin, p) {
+				squares = append(squares, p)
+			}
+		}
+	}
+	return squares
+}
+
+// EntitiesIn lists the entities on the grid inside the template, in grid order
+func (g *Grid) EntitiesIn(t AreaTemplate) []*Entity {
+	var entities []*Entity
+	for _, p := range g.Squares(t) {
+		if e := g.GetEntityAt(p); e != nil {
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+// inTemplate reports whether a square is inside the template
+func (g *Grid) inTemplate(t AreaTemplate, p Position) bool {
+	switch t.Shape {
+	case Burst:
+		// Count the squares out from the corner on each axis, including the one touching it
+		dx := p.X - t.Origin.X + 1
+		if p.X < t.Origin.X {
+			dx = t.Origin.X - p.X
+		}
+		dy := p.Y - t.Origin.Y + 1
+		if p.Y < t.Origin.Y {
+			dy = t.Origin.Y - p.Y
+		}
+		return g.CalculateDistance(Position{}, Position{X: dx, Y: dy}) <= t.Size
+	case Emanation:
+		return g.CalculateDistance(t.Origin, p) <= t.Size
+	case Cone:
+		step, ok := directionSteps[t.Direction]
+		if !ok || p == t.Origin || g.CalculateDistance(t.Origin, p) > t.Size {
+			return false
+		}
+		dx, dy := p.X-t.Origin.X, p.Y-t.Origin.Y
+		if step.X != 0 && step.Y != 0 {
+			// Diagonal cones cover the quadrant between the two directions
+			return dx*step.X > 0 && dy*step.Y > 0
+		}
+		// Straight cones widen by a square on each side for every square forward
+		forward, sideways := dx*step.X+dy*step.Y, abs(dx*step.Y)+abs(dy*step.X)
+		return forward > 0 && sideways < forward
+	case Line:
+		step, ok := directionSteps[t.Direction]
+		if !ok {
+			return false
+		}
+		for current := t.Origin; ; {
+			current = Position{X: current.X + step.X, Y: current.Y + step.Y}
+			if g.CalculateDistance(t.Origin, current) > t.Size || !g.IsValidPosition(current) {
+				return false
+			}
+			if current == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AreaStep is taken when an area effect is placed, before it affects anyone
+type AreaStep struct {
+	BaseStep
+	Source   *Entity
+	Template AreaTemplate
+	Squares  []Position
+	Targets  []*Entity
+}
+
+func NewAreaStep(source *Entity, template AreaTemplate, squares []Position, targets []*Entity) AreaStep {
+	names := make([]string, 0, len(targets))
+	for _, t := range targets {
+		names = append(names, t.Name)
+	}
+	return AreaStep{
+		BaseStep: BaseStep{
+			StepType: AreaEffect,
+			metadata: map[string]interface{}{
+				"Source":    entityName(source),
+				"Area":      template.Area.String(),
+				"Origin":    [2]int{template.Origin.X, template.Origin.Y},
+				"Direction": string(template.Direction),
+				"Targets":   names,
+			},
+		},
+		Source:   source,
+		Template: template,
+		Squares:  squares,
+		Targets:  targets,
+	}
+}
+
+// PlaceArea places an area template on the grid, taking an area step so
+// listeners can see the squares it covers, and returns the entities inside
+func PlaceArea(gs *GameState, source *Entity, template AreaTemplate) []*Entity {
+	squares := gs.Grid.Squares(template)
+	targets := gs.Grid.EntitiesIn(template)
+	executeStep(gs, NewAreaStep(source, template, squares, targets),
+		fmt.Sprintf("%s creates a %s at (%d,%d) covering %d squares.", entityName(source), template.Area, template.Origin.X, template.Origin.Y, len(squares)))
+	return targets
+}
+
+const (
+	OriginParam    = "origin"    // The square an area starts from, as [x, y]
+	DirectionParam = "direction" // The direction a cone or line points, e.g. "NE"
+)
+
+// areaTemplate builds the template an area card's params describe. Bursts
+// need an origin within range of the actor and in its line of effect; cones and lines need a direction
+// and come from the actor's square, as do emanations.
+func areaTemplate(gs *GameState, actor *Entity, area Area, rangeFeet int, params map[string]interface{}) (AreaTemplate, error) {
+	template := AreaTemplate{Area: area, Origin: gs.Grid.GetEntityPosition(actor)}
+	switch area.Shape {
+	case Burst:
+		origin, err := getSquare(gs, actor, OriginParam, []SquareCriterion{InBounds(), SquareRange(rangeFeet), LineOfEffect()}, params)
+		if err != nil {
+			return template, fmt.Errorf("origin: %w", err)
+		}
+		template.Origin = origin
+	case Cone, Line:
+		name, _ := params[DirectionParam].(string)
+		if name == "" {
+			return template, errors.New("direction not found in params")
+		}
+		direction, err := ParseDirection(name)
+		if err != nil {
+			return template, err
+		}
+		template.Direction = direction
+	}
+	return template, nil
+}
+
+// NewAreaActionCard creates an action card whose effect covers an area rather
+// than targeting a creature. Bursts take an "origin" square within rangeFeet
+// of the actor; cones and lines take a "direction" and come from the actor's
+// square, as emanations do. actionFunc gets every entity inside the area,
+// including the actor if it is.
+func NewAreaActionCard(
+	name string,
+	actionType ActionCardType,
+	description string,
+	area Area,
+	rangeFeet int,
+	actionFunc func(gs *GameState, actor *Entity, template AreaTemplate, targets []*Entity),
+) *ActionCard {
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        name,
+		Type:        actionType,
+		Description: description,
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			template, err := areaTemplate(gs, actor, area, rangeFeet, params)
+			if err != nil {
+				return Action{}, err
+			}
+			return Action{
+				Name: name,
+				Cost: actionType.ToCost(params),
+				perform: func(gs *GameState, actor *Entity) {
+					actionFunc(gs, actor, template, PlaceArea(gs, actor, template))
+				},
+			}, nil
+		},
+	}
+}
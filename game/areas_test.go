@@ -0,0 +1,144 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// picture draws the grid with the template's squares as # and walls as W
+func picture(g *Grid, t AreaTemplate) []string {
+	in := map[Position]bool{}
+	for _, p := range g.Squares(t) {
+		in[p] = true
+	}
+	var rows []string
+	for y := 0; y < g.Height; y++ {
+		var row strings.Builder
+		for x := 0; x < g.Width; x++ {
+			switch p := (Position{X: x, Y: y}); {
+			case in[p]:
+				row.WriteByte('#')
+			case g.IsWall(p):
+				row.WriteByte('W')
+			default:
+				row.WriteByte('.')
+			}
+		}
+		rows = append(rows, row.String())
+	}
+	return rows
+}
+
+func TestAreaSquares(t *testing.T) {
+	tests := []struct {
+		name     string
+		template AreaTemplate
+		walls    []Position
+		want     []string
+	}{
+		{"10-foot burst from the corner of (3,3)", AreaTemplate{Area: Area{Shape: Burst, Size: 10}, Origin: Position{X: 3, Y: 3}}, nil, []string{
+			".......",
+			"..##...",
+			".####..",
+			".####..",
+			"..##...",
+			".......",
+			".......",
+		}},
+		{"5-foot emanation", AreaTemplate{Area: Area{Shape: Emanation, Size: 5}, Origin: Position{X: 3, Y: 3}}, nil, []string{
+			".......",
+			".......",
+			"..###..",
+			"..###..",
+			"..###..",
+			".......",
+			".......",
+		}},
+		{"10-foot emanation", AreaTemplate{Area: Area{Shape: Emanation, Size: 10}, Origin: Position{X: 3, Y: 3}}, nil, []string{
+			".......",
+			"..###..",
+			".#####.",
+			".#####.",
+			".#####.",
+			"..###..",
+			".......",
+		}},
+		{"a wall blocks an emanation", AreaTemplate{Area: Area{Shape: Emanation, Size: 10}, Origin: Position{X: 3, Y: 3}}, []Position{{X: 4, Y: 2}}, []string{
+			".......",
+			"..##...",
+			".###W..",
+			".#####.",
+			".#####.",
+			"..###..",
+			".......",
+		}},
+		{"15-foot cone east", AreaTemplate{Area: Area{Shape: Cone, Size: 15}, Origin: Position{X: 0, Y: 3}, Direction: East}, nil, []string{
+			".......",
+			".......",
+			"..##...",
+			".###...",
+			"..##...",
+			".......",
+			".......",
+		}},
+		{"15-foot cone north", AreaTemplate{Area: Area{Shape: Cone, Size: 15}, Origin: Position{X: 3, Y: 6}, Direction: North}, nil, []string{
+			".......",
+			".......",
+			".......",
+			"..###..",
+			"..###..",
+			"...#...",
+			".......",
+		}},
+		{"15-foot cone south-east", AreaTemplate{Area: Area{Shape: Cone, Size: 15}, Origin: Position{X: 0, Y: 0}, Direction: SouthEast}, nil, []string{
+			".......",
+			".###...",
+			".##....",
+			".#.....",
+			".......",
+			".......",
+			".......",
+		}},
+		{"30-foot line east stops at the edge", AreaTemplate{Area: Area{Shape: Line, Size: 30}, Origin: Position{X: 0, Y: 5}, Direction: East}, nil, []string{
+			".......",
+			".......",
+			".......",
+			".......",
+			".......",
+			".######",
+			".......",
+		}},
+		{"15-foot line north-east", AreaTemplate{Area: Area{Shape: Line, Size: 15}, Origin: Position{X: 0, Y: 6}, Direction: NorthEast}, nil, []string{
+			".......",
+			".......",
+			".......",
+			".......",
+			"..#....",
+			".#.....",
+			".......",
+		}},
+	}
+	for _, tt := range tests {
+		g := NewGrid(7, 7)
+		for _, w := range tt.walls {
+			g.AddWall(w)
+		}
+		if got := picture(g, tt.template); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s covers\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestEntitiesInArea(t *testing.T) {
+	caster := NewEntity("Caster", 20, 15, GoodGuys)
+	near := NewEntity("Near", 20, 15, BadGuys)
+	far := NewEntity("Far", 20, 15, BadGuys)
+	gs := newTestGame([]Spawn{NewSpawn(caster, 3, 3), NewSpawn(near, 4, 4), NewSpawn(far, 6, 3)}, dice.NewSeededSource(1))
+
+	got := gs.Grid.EntitiesIn(AreaTemplate{Area: Area{Shape: Emanation, Size: 5}, Origin: Position{X: 3, Y: 3}})
+	if want := []*Entity{caster, near}; !reflect.DeepEqual(got, want) {
+		t.Errorf("a 5-foot emanation around the caster holds %v, want the caster and Near", got)
+	}
+}
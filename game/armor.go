@@ -0,0 +1,105 @@
+package game
+
+import (
+	"fmt"
+)
+
+type ArmorCategory string
+
+const (
+	Unarmored   ArmorCategory = "UNARMORED"
+	LightArmor  ArmorCategory = "LIGHT"
+	MediumArmor ArmorCategory = "MEDIUM"
+	HeavyArmor  ArmorCategory = "HEAVY"
+)
+
+// DefaultSpeed is the Speed in feet of an entity with no other Speed given
+const DefaultSpeed = 25
+
+// Armor is armor an entity can wear. It only changes the AC and skills of
+// entities with attributes; the AC given to NewEntity already includes armor.
+type Armor struct {
+	Name         string
+	Category     ArmorCategory
+	ACBonus      int // Item bonus to AC
+	DexCap       int // Highest Dexterity modifier that applies to AC
+	CheckPenalty int // Penalty to Strength- and Dexterity-based skill checks, e.g. -2
+	SpeedPenalty int // Penalty to Speed in feet, e.g. -5
+	Strength     int // Strength modifier that removes the check penalty and reduces the Speed penalty by 5 feet
+}
+
+// String renders the armor for logs, e.g. "Chain Mail (+4 AC, Dex cap +1)"
+func (a Armor) String() string {
+	return fmt.Sprintf("%s (%+d AC, Dex cap %+d)", a.Name, a.ACBonus, a.DexCap)
+}
+
+// Common armor
+var (
+	LeatherArmor   = Armor{Name: "Leather Armor", Category: LightArmor, ACBonus: 1, DexCap: 4, CheckPenalty: -1, Strength: 1}
+	StuddedLeather = Armor{Name: "Studded Leather", Category: LightArmor, ACBonus: 2, DexCap: 3, CheckPenalty: -1, Strength: 1}
+	ChainShirt     = Armor{Name: "Chain Shirt", Category: LightArmor, ACBonus: 2, DexCap: 3, CheckPenalty: -1, Strength: 1}
+	HideArmor      = Armor{Name: "Hide Armor", Category: MediumArmor, ACBonus: 3, DexCap: 2, CheckPenalty: -2, SpeedPenalty: -5, Strength: 2}
+	ScaleMail      = Armor{Name: "Scale Mail", Category: MediumArmor, ACBonus: 3, DexCap: 2, CheckPenalty: -2, SpeedPenalty: -5, Strength: 2}
+	ChainMail      = Armor{Name: "Chain Mail", Category: MediumArmor, ACBonus: 4, DexCap: 1, CheckPenalty: -2, SpeedPenalty: -5, Strength: 3}
+	Breastplate    = Armor{Name: "Breastplate", Category: MediumArmor, ACBonus: 4, DexCap: 1, CheckPenalty: -2, SpeedPenalty: -5, Strength: 3}
+	SplintMail     = Armor{Name: "Splint Mail", Category: HeavyArmor, ACBonus: 5, DexCap: 1, CheckPenalty: -3, SpeedPenalty: -10, Strength: 3}
+	FullPlate      = Armor{Name: "Full Plate", Category: HeavyArmor, ACBonus: 6, DexCap: 0, CheckPenalty: -3, SpeedPenalty: -10, Strength: 4}
+)
+
+// WearArmor puts on armor, replacing any the entity was wearing
+func (e *Entity) WearArmor(a Armor) {
+	e.Armor = &a
+}
+
+// RemoveArmor takes off the entity's armor
+func (e *Entity) RemoveArmor() {
+	e.Armor = nil
+}
+
+// meetsStrength reports whether the entity is strong enough to wear its armor without hindrance
+func (e *Entity) meetsStrength() bool {
+	return e.Armor != nil && e.Attributes != nil && e.Attributes.Strength >= e.Armor.Strength
+}
+
+// LandSpeed is the entity's Speed in feet after its armor's Speed penalty
+func (e *Entity) LandSpeed() int {
+	speed := e.Speed
+	if speed == 0 {
+		speed = DefaultSpeed
+	}
+	if e.Armor != nil && e.Armor.SpeedPenalty < 0 {
+		penalty := e.Armor.SpeedPenalty
+		if e.meetsStrength() {
+			penalty = min(0, penalty+5)
+		}
+		speed += penalty
+	}
+	return max(5, speed)
+}
+
+// armorModifiers are the modifiers the entity's armor gives a statistic.
+// Checks with the attack trait, such as Trip, ignore the check penalty.
+func (e *Entity) armorModifiers(stat Statistic, attackTrait bool) []Modifier {
+	if e.Armor == nil || e.Attributes == nil {
+		return nil
+	}
+	switch {
+	case stat == StatAC:
+		return []Modifier{{Source: e.Armor.Name, Type: ItemModifier, Value: e.Armor.ACBonus}}
+	case isSkill(stat) && !attackTrait && !e.meetsStrength():
+		if attribute := statisticAttributes[stat]; attribute == Strength || attribute == Dexterity {
+			return []Modifier{{Source: e.Armor.Name, Type: UntypedModifier, Value: e.Armor.CheckPenalty}}
+		}
+	}
+	return nil
+}
+
+// isSkill reports whether the statistic is a skill
+func isSkill(stat Statistic) bool {
+	for _, skill := range Skills {
+		if Statistic(skill) == stat {
+			return true
+		}
+	}
+	return false
+}
@@ -24,6 +24,15 @@ func NewAttributes(strength, dexterity, constitution, intelligence, wisdom, char
 	}
 }
 
+// copy returns a copy of the attributes, or nil for entities without them
+func (a *Attributes) copy() *Attributes {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	return &c
+}
+
 // ApplyModifiers adjusts attributes dynamically based on external factors
 func (a *Attributes) ApplyModifiers(modifiers map[string]int) {
 	if mod, ok := modifiers["Strength"]; ok {
@@ -45,3 +54,35 @@ func (a *Attributes) ApplyModifiers(modifiers map[string]int) {
 		a.Charisma += mod
 	}
 }
+
+// AttributeName identifies one of the six attributes
+type AttributeName string
+
+const (
+	Strength     AttributeName = "STRENGTH"
+	Dexterity    AttributeName = "DEXTERITY"
+	Constitution AttributeName = "CONSTITUTION"
+	Intelligence AttributeName = "INTELLIGENCE"
+	Wisdom       AttributeName = "WISDOM"
+	Charisma     AttributeName = "CHARISMA"
+)
+
+// Modifier returns the modifier of the named attribute
+func (a *Attributes) Modifier(name AttributeName) int {
+	switch name {
+	case Strength:
+		return a.Strength
+	case Dexterity:
+		return a.Dexterity
+	case Constitution:
+		return a.Constitution
+	case Intelligence:
+		return a.Intelligence
+	case Wisdom:
+		return a.Wisdom
+	case Charisma:
+		return a.Charisma
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,274 @@
+package game
+
+import (
+	"fmt"
+	dice "pf2eEngine/util"
+)
+
+type Skill string
+
+const (
+	Acrobatics   Skill = "ACROBATICS"
+	Arcana       Skill = "ARCANA"
+	Athletics    Skill = "ATHLETICS"
+	Crafting     Skill = "CRAFTING"
+	Deception    Skill = "DECEPTION"
+	Diplomacy    Skill = "DIPLOMACY"
+	Intimidation Skill = "INTIMIDATION"
+	Medicine     Skill = "MEDICINE"
+	Nature       Skill = "NATURE"
+	Occultism    Skill = "OCCULTISM"
+	Performance  Skill = "PERFORMANCE"
+	Religion     Skill = "RELIGION"
+	Society      Skill = "SOCIETY"
+	Stealth      Skill = "STEALTH"
+	Survival     Skill = "SURVIVAL"
+	Thievery     Skill = "THIEVERY"
+)
+
+// Skills lists every skill
+var Skills = []Skill{
+	Acrobatics, Arcana, Athletics, Crafting, Deception, Diplomacy, Intimidation, Medicine,
+	Nature, Occultism, Performance, Religion, Society, Stealth, Survival, Thievery,
+}
+
+// Statistic identifies a modifier a check can roll or a DC can be built from.
+// Saves and skills convert directly, e.g. Statistic(Fortitude) or Statistic(Athletics).
+type Statistic string
+
+const (
+	StatAC         Statistic = "AC"
+	StatPerception Statistic = "PERCEPTION"
+	StatClassDC    Statistic = "CLASS_DC"
+	StatAttack     Statistic = "ATTACK"
+)
+
+// isDC reports whether the statistic is a DC that starts from 10 rather than a modifier
+func (s Statistic) isDC() bool {
+	return s == StatAC || s == StatClassDC || s == StatSpellDC
+}
+
+type Proficiency int
+
+const (
+	Untrained Proficiency = iota
+	Trained
+	Expert
+	Master
+	Legendary
+)
+
+func (p Proficiency) String() string {
+	switch p {
+	case Untrained:
+		return "Untrained"
+	case Trained:
+		return "Trained"
+	case Expert:
+		return "Expert"
+	case Master:
+		return "Master"
+	case Legendary:
+		return "Legendary"
+	default:
+		return "Unknown"
+	}
+}
+
+// levelBasedDCs holds the DC for each level from -1 to 25
+var levelBasedDCs = []int{13, 14, 15, 16, 18, 19, 20, 22, 23, 24, 26, 27, 28, 30, 31, 32, 34, 35, 36, 38, 39, 40, 42, 44, 46, 48, 50}
+
+// LevelBasedDC returns the standard DC for a task of the given level
+func LevelBasedDC(level int) int {
+	if level < -1 {
+		level = -1
+	}
+	if level > 25 {
+		level = 25
+	}
+	return levelBasedDCs[level+1]
+}
+
+// SimpleDC returns the DC of a task that only depends on the proficiency it calls for
+func SimpleDC(rank Proficiency) int {
+	switch rank {
+	case Untrained:
+		return 10
+	case Trained:
+		return 15
+	case Expert:
+		return 20
+	case Master:
+		return 30
+	default:
+		return 40
+	}
+}
+
+// DCAdjustment makes a DC easier or harder
+type DCAdjustment int
+
+const (
+	IncrediblyEasy DCAdjustment = -10
+	VeryEasy       DCAdjustment = -5
+	Easy           DCAdjustment = -2
+	Hard           DCAdjustment = 2
+	VeryHard       DCAdjustment = 5
+	IncrediblyHard DCAdjustment = 10
+)
+
+// AdjustDC applies a difficulty adjustment to a DC
+func AdjustDC(dc int, adjustment DCAdjustment) int {
+	return dc + int(adjustment)
+}
+
+// SetSkill sets the modifier for a skill of an entity without attributes
+func (e *Entity) SetSkill(skill Skill, modifier int) {
+	if e.Skills == nil {
+		e.Skills = make(map[Skill]int)
+	}
+	e.Skills[skill] = modifier
+}
+
+// SkillModifier returns the entity's modifier for a skill
+func (e *Entity) SkillModifier(skill Skill) int {
+	return e.Modifier(Statistic(skill))
+}
+
+// Modifier returns the entity's modifier for any statistic. For AC and class
+// DC that is the DC less 10.
+func (e *Entity) Modifier(stat Statistic) int {
+	b := e.Breakdown(stat)
+	return b.Total - b.Base
+}
+
+// DC returns the DC others roll against to affect this entity with a statistic,
+// e.g. its Fortitude DC for a Trip or its Will DC for a Demoralize
+func (e *Entity) DC(stat Statistic) int {
+	return 10 + e.Modifier(stat)
+}
+
+// Check is a single d20 check against a DC
+type Check struct {
+	Roller    *Entity
+	Target    *Entity // Whose DC the check is against, if anyone's
+	Name      string  // What is being rolled, e.g. "Athletics" or "Flat"
+	Modifiers []dice.Modifier
+	DC        int
+	Roll      int
+	Result    int
+	Degree    DegreeOfSuccess
+	Record    dice.RollRecord
+}
+
+type BeforeCheckStep struct {
+	BaseStep
+	Check *Check
+}
+
+type AfterCheckStep struct {
+	BaseStep
+	Check *Check
+}
+
+func NewBeforeCheckStep(check *Check) BeforeCheckStep {
+	return BeforeCheckStep{
+		BaseStep: BaseStep{
+			StepType: BeforeCheck,
+			metadata: map[string]interface{}{
+				"Roller": check.Roller.Name,
+				"Check":  check.Name,
+				"DC":     check.DC,
+			},
+		},
+		Check: check,
+	}
+}
+
+func NewAfterCheckStep(check *Check) AfterCheckStep {
+	return AfterCheckStep{
+		BaseStep: BaseStep{
+			StepType: AfterCheck,
+			metadata: map[string]interface{}{
+				"Roller": check.Roller.Name,
+				"Check":  check.Name,
+				"DC":     check.DC,
+				"Roll":   check.Record.String(),
+				"Rolls":  d20Faces(check.Record),
+				"Result": check.Result,
+				"Degree": check.Degree,
+			},
+		},
+		Check: check,
+	}
+}
+
+// ResolveCheck rolls the check and works out its degree of success. Triggers on
+// BEFORE_CHECK may change the DC or add modifiers before the die is rolled.
+func ResolveCheck(gs *GameState, check *Check) *Check {
+	executeStep(gs, NewBeforeCheckStep(check), fmt.Sprintf("%s attempts a %s check against DC %d.", check.Roller.Name, check.Name, check.DC))
+
+	check.Record = rollD20(gs, check.Roller, check.Modifiers)
+	check.Roll = d20Face(check.Record)
+	check.Result = check.Record.Total
+	check.Degree = calculateDegreeOfSuccess(check.Roll, check.Result, check.DC)
+
+	executeStep(gs, NewAfterCheckStep(check), fmt.Sprintf("%s rolls %s vs DC %d on a %s check: %s.",
+		check.Roller.Name, check.Record.String(), check.DC, check.Name, check.Degree))
+	return check
+}
+
+// SkillCheck has the entity attempt a skill check against a DC
+func SkillCheck(gs *GameState, e *Entity, skill Skill, dc int) *Check {
+	return statisticCheck(gs, e, Statistic(skill), nil, dc)
+}
+
+// PerceptionCheck has the entity attempt a Perception check against a DC
+func PerceptionCheck(gs *GameState, e *Entity, dc int) *Check {
+	return statisticCheck(gs, e, StatPerception, nil, dc)
+}
+
+// CheckAgainst has the entity roll a statistic against one of the target's DCs,
+// e.g. Athletics against Fortitude DC to Trip or Intimidation against Will DC to Demoralize
+func CheckAgainst(gs *GameState, e *Entity, stat Statistic, target *Entity, defense Statistic) *Check {
+	return statisticCheck(gs, e, stat, target, target.DC(defense))
+}
+
+// FlatCheck rolls a d20 with no modifiers against a DC
+func FlatCheck(gs *GameState, e *Entity, dc int) *Check {
+	return ResolveCheck(gs, &Check{Roller: e, Name: "Flat", DC: dc})
+}
+
+func statisticCheck(gs *GameState, e *Entity, stat Statistic, target *Entity, dc int) *Check {
+	name := statisticName(stat)
+	return ResolveCheck(gs, &Check{
+		Roller:    e,
+		Target:    target,
+		Name:      name,
+		Modifiers: e.Breakdown(stat).DiceModifiers(),
+		DC:        dc,
+	})
+}
+
+// statisticName turns a statistic such as "ATHLETICS" into "Athletics" for logs
+func statisticName(stat Statistic) string {
+	switch stat {
+	case StatAC:
+		return "AC"
+	case StatClassDC:
+		return "Class DC"
+	case StatSpellAttack:
+		return "Spell Attack"
+	case StatSpellDC:
+		return "Spell DC"
+	case Statistic(Fortitude), Statistic(Reflex), Statistic(Will):
+		return saveName(SaveType(stat))
+	}
+	name := []byte(string(stat))
+	for i := 1; i < len(name); i++ {
+		if name[i] >= 'A' && name[i] <= 'Z' {
+			name[i] += 'a' - 'A'
+		}
+	}
+	return string(name)
+}
@@ -0,0 +1,313 @@
+package game
+
+import (
+	"fmt"
+)
+
+type ConditionName string
+
+const (
+	Clumsy      ConditionName = "CLUMSY"
+	Enfeebled   ConditionName = "ENFEEBLED"
+	Stupefied   ConditionName = "STUPEFIED"
+	Frightened  ConditionName = "FRIGHTENED"
+	Sickened    ConditionName = "SICKENED"
+	Fatigued    ConditionName = "FATIGUED"
+	OffGuard    ConditionName = "OFF_GUARD"
+	Prone       ConditionName = "PRONE"
+	Stunned     ConditionName = "STUNNED"
+	Slowed      ConditionName = "SLOWED"
+	Grabbed     ConditionName = "GRABBED"
+	Restrained  ConditionName = "RESTRAINED"
+	Immobilized ConditionName = "IMMOBILIZED"
+)
+
+// Condition is a condition an entity has, e.g. frightened 2 or prone
+type Condition struct {
+	Name     ConditionName
+	Value    int         // For valued conditions such as frightened 2; zero otherwise
+	Duration int         // Rounds left, counted down at the end of the entity's turns; zero lasts until removed
+	Source   string      // What imposed the condition, e.g. "Demoralize"
+	Damage   *DamageRoll // The damage dealt each turn by persistent damage
+}
+
+// impliedConditions are the conditions that come with another, e.g. a grabbed
+// creature is also off-guard and immobilized
+var impliedConditions = map[ConditionName][]ConditionName{
+	Prone:       {OffGuard},
+	Grabbed:     {OffGuard, Immobilized},
+	Restrained:  {OffGuard, Immobilized},
+	Unconscious: {OffGuard, Prone},
+}
+
+// String renders the condition for logs, e.g. "frightened 2"
+func (c Condition) String() string {
+	if c.Damage != nil {
+		return c.Damage.String()
+	}
+	name := conditionName(c.Name)
+	if c.Value > 0 {
+		name = fmt.Sprintf("%s %d", name, c.Value)
+	}
+	if c.Duration > 0 {
+		name = fmt.Sprintf("%s for %d rounds", name, c.Duration)
+	}
+	return name
+}
+
+// AddCondition gives the entity a condition. A condition the entity already
+// has is not doubled up: the higher value applies and, for equal values, the
+// longer duration. Persistent damage of different types is tracked separately,
+// and of the same type the higher amount applies.
+func (e *Entity) AddCondition(c Condition) {
+	for i, existing := range e.Conditions {
+		if !sameCondition(existing, c) {
+			continue
+		}
+		if c.Damage != nil {
+			if c.Damage.average() > existing.Damage.average() {
+				e.Conditions[i] = c
+			}
+			return
+		}
+		if c.Value > existing.Value || (c.Value == existing.Value && outlasts(c, existing)) {
+			e.Conditions[i] = c
+		}
+		return
+	}
+	e.Conditions = append(e.Conditions, c)
+}
+
+func sameCondition(a, b Condition) bool {
+	if a.Name != b.Name {
+		return false
+	}
+	if a.Damage != nil && b.Damage != nil {
+		return a.Damage.Type == b.Damage.Type
+	}
+	return true
+}
+
+// outlasts reports whether a lasts longer than b; conditions without a duration last the longest
+func outlasts(a, b Condition) bool {
+	if b.Duration == 0 {
+		return false
+	}
+	return a.Duration == 0 || a.Duration > b.Duration
+}
+
+// RemoveCondition removes a condition from the entity
+func (e *Entity) RemoveCondition(name ConditionName) {
+	kept := e.Conditions[:0]
+	for _, c := range e.Conditions {
+		if c.Name != name {
+			kept = append(kept, c)
+		}
+	}
+	e.Conditions = kept
+}
+
+// ReduceCondition lowers a valued condition, removing it when it reaches zero
+func (e *Entity) ReduceCondition(name ConditionName, by int) {
+	for i, c := range e.Conditions {
+		if c.Name == name {
+			e.Conditions[i].Value -= by
+			if e.Conditions[i].Value <= 0 {
+				e.RemoveCondition(name)
+			}
+			return
+		}
+	}
+}
+
+// setConditionValue sets a valued condition regardless of its current value,
+// removing it when the value is zero or less
+func (e *Entity) setConditionValue(name ConditionName, value int) {
+	if value <= 0 {
+		e.RemoveCondition(name)
+		return
+	}
+	for i, c := range e.Conditions {
+		if c.Name == name {
+			e.Conditions[i].Value = value
+			return
+		}
+	}
+	e.Conditions = append(e.Conditions, Condition{Name: name, Value: value})
+}
+
+// Condition returns the condition with the given name, if the entity has it directly
+func (e *Entity) Condition(name ConditionName) (Condition, bool) {
+	for _, c := range e.Conditions {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Condition{}, false
+}
+
+// HasCondition reports whether the entity has the condition, directly or
+// because another of its conditions implies it
+func (e *Entity) HasCondition(name ConditionName) bool {
+	for _, c := range e.Conditions {
+		if c.Name == name {
+			return true
+		}
+		for _, implied := range impliedConditions[c.Name] {
+			if implied == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ConditionValue returns the value of a valued condition, or zero if the entity doesn't have it
+func (e *Entity) ConditionValue(name ConditionName) int {
+	c, _ := e.Condition(name)
+	return c.Value
+}
+
+// conditionModifiers are the penalties the entity's conditions give a statistic
+// based on attribute. Stacking rules in the breakdown keep only the worst
+// penalty of each type.
+func (e *Entity) conditionModifiers(stat Statistic, attribute AttributeName) []Modifier {
+	var modifiers []Modifier
+	penalty := func(name ConditionName, t ModifierType, value int) {
+		modifiers = append(modifiers, Modifier{Source: conditionName(name), Type: t, Value: -value})
+	}
+
+	// Frightened and sickened apply to every check and DC
+	for _, name := range []ConditionName{Frightened, Sickened} {
+		if value := e.ConditionValue(name); value > 0 {
+			penalty(name, StatusModifier, value)
+		}
+	}
+
+	// Clumsy, enfeebled and stupefied apply to statistics based on their attributes
+	switch attribute {
+	case Dexterity:
+		if value := e.ConditionValue(Clumsy); value > 0 {
+			penalty(Clumsy, StatusModifier, value)
+		}
+	case Strength:
+		if value := e.ConditionValue(Enfeebled); value > 0 {
+			penalty(Enfeebled, StatusModifier, value)
+		}
+	case Intelligence, Wisdom, Charisma:
+		if value := e.ConditionValue(Stupefied); value > 0 {
+			penalty(Stupefied, StatusModifier, value)
+		}
+	}
+
+	// Unconscious creatures take a -4 status penalty to AC, Perception and Reflex
+	if e.HasCondition(Unconscious) && (stat == StatAC || stat == StatPerception || stat == Statistic(Reflex)) {
+		penalty(Unconscious, StatusModifier, 4)
+	}
+
+	switch stat {
+	case StatAC:
+		if e.HasCondition(OffGuard) {
+			penalty(OffGuard, CircumstanceModifier, 2)
+		}
+		if e.HasCondition(Fatigued) {
+			penalty(Fatigued, StatusModifier, 1)
+		}
+	case Statistic(Fortitude), Statistic(Reflex), Statistic(Will):
+		if e.HasCondition(Fatigued) {
+			penalty(Fatigued, StatusModifier, 1)
+		}
+	case StatAttack:
+		if e.HasCondition(Prone) {
+			penalty(Prone, CircumstanceModifier, 2)
+		}
+	}
+	return modifiers
+}
+
+// startTurnConditions takes away the actions the entity loses to being stunned
+// or slowed. Stunned overrides slowed, but actions lost to stunned count
+// towards those lost to slowed.
+func (gs *GameState) startTurnConditions(e *Entity) {
+	lost, cause := 0, Stunned
+	if stunned, ok := e.Condition(Stunned); ok {
+		if stunned.Value > 0 {
+			lost = min(stunned.Value, e.ActionsRemaining)
+			e.ReduceCondition(Stunned, lost)
+		} else {
+			lost = e.ActionsRemaining
+		}
+	}
+	if slowed := min(e.ConditionValue(Slowed), e.ActionsRemaining); slowed > lost {
+		lost, cause = slowed, Slowed
+	}
+	if lost > 0 {
+		e.ActionsRemaining -= lost
+		gs.Printf("%s loses %d actions to being %s.\n", e.Name, lost, conditionName(cause))
+	}
+}
+
+// endTurnConditions deals persistent damage, counts down condition durations
+// and lowers frightened by 1 at the end of the entity's turn
+func (gs *GameState) endTurnConditions(e *Entity) {
+	gs.persistentDamage(e)
+
+	var ended []Condition
+	kept := e.Conditions[:0]
+	for _, c := range e.Conditions {
+		if c.Name == Frightened {
+			c.Value--
+			if c.Value <= 0 {
+				ended = append(ended, c)
+				continue
+			}
+		}
+		if c.Duration > 0 {
+			c.Duration--
+			if c.Duration == 0 {
+				ended = append(ended, c)
+				continue
+			}
+		}
+		kept = append(kept, c)
+	}
+	e.Conditions = kept
+	for _, c := range ended {
+		gs.Printf("%s is no longer %s.\n", e.Name, conditionName(c.Name))
+	}
+}
+
+// copyConditions copies a list of conditions, including their persistent damage
+func copyConditions(conditions []Condition) []Condition {
+	if conditions == nil {
+		return nil
+	}
+	c := make([]Condition, len(conditions))
+	for i, condition := range conditions {
+		if condition.Damage != nil {
+			damage := *condition.Damage
+			condition.Damage = &damage
+		}
+		c[i] = condition
+	}
+	return c
+}
+
+// conditionName turns "OFF_GUARD" into "off-guard" for logs
+func conditionName(name ConditionName) string {
+	return displayName(string(name))
+}
+
+// displayName turns an identifier such as "OFF_GUARD" or "FIRE" into lower case words for logs
+func displayName(name string) string {
+	b := []byte(name)
+	for i := range b {
+		switch {
+		case b[i] == '_':
+			b[i] = '-'
+		case b[i] >= 'A' && b[i] <= 'Z':
+			b[i] += 'a' - 'A'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+	"reflect"
+	"testing"
+)
+
+func TestAddConditionKeepsTheStrongest(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing Condition
+		added    Condition
+		want     Condition
+	}{
+		{"higher value replaces", Condition{Name: Frightened, Value: 1}, Condition{Name: Frightened, Value: 2}, Condition{Name: Frightened, Value: 2}},
+		{"lower value is ignored", Condition{Name: Frightened, Value: 2}, Condition{Name: Frightened, Value: 1}, Condition{Name: Frightened, Value: 2}},
+		{"higher value wins over a longer duration", Condition{Name: Clumsy, Value: 1, Duration: 5}, Condition{Name: Clumsy, Value: 2, Duration: 1}, Condition{Name: Clumsy, Value: 2, Duration: 1}},
+		{"lower value loses despite a longer duration", Condition{Name: Clumsy, Value: 2, Duration: 1}, Condition{Name: Clumsy, Value: 1, Duration: 5}, Condition{Name: Clumsy, Value: 2, Duration: 1}},
+		{"equal value, longer duration replaces", Condition{Name: Clumsy, Value: 1, Duration: 2}, Condition{Name: Clumsy, Value: 1, Duration: 3}, Condition{Name: Clumsy, Value: 1, Duration: 3}},
+		{"equal value, shorter duration is ignored", Condition{Name: Clumsy, Value: 1, Duration: 2}, Condition{Name: Clumsy, Value: 1, Duration: 1}, Condition{Name: Clumsy, Value: 1, Duration: 2}},
+		{"unlimited outlasts a duration", Condition{Name: Clumsy, Value: 1, Duration: 2}, Condition{Name: Clumsy, Value: 1}, Condition{Name: Clumsy, Value: 1}},
+		{"a duration doesn't replace unlimited", Condition{Name: Prone}, Condition{Name: Prone, Duration: 3}, Condition{Name: Prone}},
+	}
+	for _, tt := range tests {
+		e := NewEntity("Target", 20, 15, BadGuys)
+		e.AddCondition(tt.existing)
+		e.AddCondition(tt.added)
+		if !reflect.DeepEqual(e.Conditions, []Condition{tt.want}) {
+			t.Errorf("%s: conditions = %v, want [%v]", tt.name, e.Conditions, tt.want)
+		}
+	}
+}
+
+func TestReduceCondition(t *testing.T) {
+	tests := []struct {
+		value, by, want int
+	}{
+		{3, 1, 2},
+		{2, 2, 0},
+		{1, 3, 0},
+	}
+	for _, tt := range tests {
+		e := NewEntity("Target", 20, 15, BadGuys)
+		e.AddCondition(Condition{Name: Frightened, Value: tt.value})
+		e.ReduceCondition(Frightened, tt.by)
+		if got := e.ConditionValue(Frightened); got != tt.want {
+			t.Errorf("frightened %d reduced by %d = %d, want %d", tt.value, tt.by, got, tt.want)
+		}
+		if tt.want == 0 && len(e.Conditions) != 0 {
+			t.Errorf("frightened %d reduced by %d left %v", tt.value, tt.by, e.Conditions)
+		}
+	}
+}
+
+func TestEndTurnConditions(t *testing.T) {
+	tests := []struct {
+		name  string
+		start []Condition
+		want  []Condition
+	}{
+		{"frightened drops by 1", []Condition{{Name: Frightened, Value: 2}}, []Condition{{Name: Frightened, Value: 1}}},
+		{"frightened 1 ends", []Condition{{Name: Frightened, Value: 1}}, nil},
+		{"other values stay", []Condition{{Name: Clumsy, Value: 2}, {Name: Sickened, Value: 1}}, []Condition{{Name: Clumsy, Value: 2}, {Name: Sickened, Value: 1}}},
+		{"durations count down", []Condition{{Name: Slowed, Value: 1, Duration: 2}}, []Condition{{Name: Slowed, Value: 1, Duration: 1}}},
+		{"last round ends", []Condition{{Name: Slowed, Value: 1, Duration: 1}, {Name: Prone}}, []Condition{{Name: Prone}}},
+	}
+	for _, tt := range tests {
+		e := NewEntity("Target", 20, 15, BadGuys)
+		gs := newTestGame([]Spawn{NewSpawn(e, 0, 0)}, dice.NewSeededSource(1))
+		for _, c := range tt.start {
+			e.AddCondition(c)
+		}
+		gs.endTurnConditions(e)
+		if len(e.Conditions) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(e.Conditions, tt.want) {
+			t.Errorf("%s: conditions = %v, want %v", tt.name, e.Conditions, tt.want)
+		}
+	}
+}
+
+func TestConditionPenalties(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []Condition
+		ac, attack int
+	}{
+		{"none", nil, 15, 0},
+		{"frightened", []Condition{{Name: Frightened, Value: 2}}, 13, -2},
+		{"status penalties don't stack", []Condition{{Name: Frightened, Value: 2}, {Name: Sickened, Value: 1}}, 13, -2},
+		{"prone is off-guard", []Condition{{Name: Prone}}, 13, -2},
+		{"status and circumstance stack", []Condition{{Name: Frightened, Value: 1}, {Name: Prone}}, 12, -3},
+		{"clumsy hits Dexterity-based AC", []Condition{{Name: Clumsy, Value: 2}}, 13, 0},
+		{"enfeebled hits Strength-based attacks", []Condition{{Name: Enfeebled, Value: 1}}, 15, -1},
+		{"fatigued", []Condition{{Name: Fatigued}}, 14, 0},
+		{"worst status penalty applies", []Condition{{Name: Clumsy, Value: 3}, {Name: Frightened, Value: 1}}, 12, -1},
+	}
+	for _, tt := range tests {
+		e := NewEntity("Target", 20, 15, BadGuys)
+		e.Wield(Longsword, 1)
+		for _, c := range tt.conditions {
+			e.AddCondition(c)
+		}
+		if got := e.ArmorClass(); got != tt.ac {
+			t.Errorf("%s: AC = %d, want %d", tt.name, got, tt.ac)
+		}
+		if got := e.StrikeBreakdown(e.Weapons[0], nil, false).Total; got != tt.attack {
+			t.Errorf("%s: attack = %d, want %d", tt.name, got, tt.attack)
+		}
+	}
+}
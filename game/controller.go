@@ -1,60 +1,88 @@
 package game
 
 import (
-	"fmt"
 	"time"
 )
 
 // Controller interface for entity controllers
 type Controller interface {
 	NextAction(gs *GameState, entity *Entity) Action
+	// ChooseReaction picks one of the request's options by index, or
+	// DeclineReaction to take none of them
+	ChooseReaction(gs *GameState, entity *Entity, req ReactionRequest) int
+}
+
+// CombatOptions controls how a combat is run
+type CombatOptions struct {
+	ActionDelay time.Duration // Pause after each action so spectators can follow along
+	TurnDelay   time.Duration // Pause after each turn
+	MaxRounds   int           // Stop after this many rounds; zero runs until one faction is left
+}
+
+// LiveCombatOptions paces a combat for people watching it through the frontend
+var LiveCombatOptions = CombatOptions{
+	ActionDelay: 500 * time.Millisecond,
+	TurnDelay:   1 * time.Second,
 }
 
 // RunCombat runs the combat simulation automatically
 func RunCombat(gs *GameState) {
-	fmt.Println("Starting combat simulation...")
+	RunCombatWithOptions(gs, LiveCombatOptions)
+}
+
+// RunCombatWithOptions runs the combat until one faction is left standing or
+// the round limit is reached
+func RunCombatWithOptions(gs *GameState, opts CombatOptions) {
+	gs.Printf("Starting combat simulation...\n")
 	gs.StartCombat()
-	
+
 	// Run combat until someone wins or we stop manually
-	for {
+	for !gs.checkCombatOver() {
+		if opts.MaxRounds > 0 && gs.Round > opts.MaxRounds {
+			gs.Printf("Combat stopped after %d rounds.\n", opts.MaxRounds)
+			break
+		}
+
 		// Get current entity
 		entity := gs.GetCurrentTurnEntity()
 		if entity == nil {
-			fmt.Println("Combat is over, no more entities.")
+			gs.Printf("Combat is over, no more entities.\n")
 			break
 		}
-		
+
 		// Skip if entity is dead
 		if !entity.IsAlive() {
 			gs.NextTurn()
 			continue
 		}
-		
+
 		// Get controller decision for AI entities
 		controller := entity.Controller
 		if controller != nil {
 			// Process all actions for this entity's turn
-			for entity.ActionsRemaining > 0 {
+			for entity.ActionsRemaining > 0 && !gs.checkCombatOver() {
 				// Let the controller decide what to do
 				action := controller.NextAction(gs, entity)
-				
+
 				// If no action was chosen or it's an end turn action, move on
 				if action.Name == "" || action.Type == EndOfTurn {
 					break
 				}
-				
+
 				// Execute the action
 				ExecuteAction(gs, entity, action)
-				
+
 				// Short pause between actions
-				time.Sleep(500 * time.Millisecond)
+				time.Sleep(opts.ActionDelay)
 			}
 		}
-		
+
 		// Move to next entity
-		gs.NextTurn()
-		
+		if gs.NextTurn() == nil {
+			break
+		}
+
 		// Slight pause between turns
-		time.Sleep(1 * time.Second)
+		time.Sleep(opts.TurnDelay)
 	}
-}
\ No newline at end of file
+}
@@ -3,23 +3,109 @@ package game
 import (
 	"fmt"
 	dice "pf2eEngine/util"
+	"strings"
 )
 
 type Damage struct {
-	Source  *Entity
-	Target  *Entity
-	Amount  map[DamageType]DamageAmount
-	Blocked int
-	Taken   int
+	Source      *Entity
+	Target      *Entity
+	Amount      map[DamageType]DamageAmount
+	Rolls       []dice.RollRecord
+	Fortune     dice.Fortune      // Set when the damage was rolled twice
+	Discarded   []dice.RollRecord // The damage rolls thrown away by fortune or misfortune
+	Blocked     int               // Damage prevented, e.g. by a Shield Block
+	BlockedBy   *Shield           // The shield that blocked the damage, which takes what gets through
+	Taken       int
+	Critical    bool               // Doubled by a critical hit or critically failed save
+	Adjustments []DamageAdjustment // Immunities, weaknesses and resistances that changed the damage
+	Persistent  []DamageRoll       // Persistent damage the target starts taking
 }
 
+// Double doubles each type of damage for a critical hit or critically failed save
 func (d Damage) Double() Damage {
+	doubled := d.copyFor(d.Target)
+	doubled.Critical = true
 	for k, v := range d.Amount {
-		d.Amount[k] = DamageAmount{Amount: v.Amount * 2, Type: v.Type}
+		doubled.Amount[k] = DamageAmount{Amount: v.Amount * 2, Type: v.Type, Precision: v.Precision * 2}
 	}
+	return doubled
+}
+
+// Halve halves the damage for a successful basic save. The total is halved
+// and rounded down once, then spread back over the types, so that 3 fire and
+// 3 cold deal 3 damage rather than 1 of each.
+func (d Damage) Halve() Damage {
+	halved := d.copyFor(d.Target)
+	total := 0
+	for _, v := range d.Amount {
+		total += v.Amount
+	}
+	remaining := total / 2
+	types := damageTypesOf(d.Amount)
+	for _, t := range types {
+		v := d.Amount[t]
+		halved.Amount[t] = DamageAmount{Amount: v.Amount / 2, Type: v.Type, Precision: v.Precision / 2}
+		remaining -= v.Amount / 2
+	}
+	// Give the halves lost to rounding back to the types with odd amounts
+	for _, t := range types {
+		if remaining <= 0 {
+			break
+		}
+		if v := d.Amount[t]; v.Amount%2 == 1 {
+			halved.Amount[t] = DamageAmount{Amount: v.Amount/2 + 1, Type: v.Type, Precision: v.Precision / 2}
+			remaining--
+		}
+	}
+	return halved
+}
+
+// copyFor copies damage rolled once, e.g. for an area, so that another target
+// can take it without sharing its amounts
+func (d Damage) copyFor(target *Entity) Damage {
+	d.Target = target
+	amount := make(map[DamageType]DamageAmount, len(d.Amount))
+	for k, v := range d.Amount {
+		amount[k] = v
+	}
+	d.Amount = amount
 	return d
 }
 
+// rollDamage rolls damage from source to target, honouring the source's fortune
+// effects. source may be nil, e.g. for hazards.
+func rollDamage(gs *GameState, source *Entity, target *Entity, rolls []DamageRoll) Damage {
+	fortune := dice.NoFortune
+	if source != nil {
+		defer gs.rollAs(source)()
+		fortune = source.useFortune(DamageRollKind)
+	}
+	amount, records, discarded := BaseAttack{Damage: rolls}.RollDamageWithFortune(gs.Dice, fortune)
+	var persistent []DamageRoll
+	for _, dr := range rolls {
+		if dr.Persistent {
+			persistent = append(persistent, dr)
+		}
+	}
+	return Damage{
+		Persistent: persistent,
+		Source:     source,
+		Target:     target,
+		Amount:     amount,
+		Rolls:      records,
+		Fortune:    fortune,
+		Discarded:  discarded,
+	}
+}
+
+// entityName returns the entity's name, or a placeholder for damage with no source
+func entityName(e *Entity) string {
+	if e == nil {
+		return "Something"
+	}
+	return e.Name
+}
+
 type BeforeDamageStep struct {
 	BaseStep
 	Damage *Damage
@@ -35,25 +121,36 @@ func NewBeforeDamageStep(damage *Damage) BeforeDamageStep {
 		BaseStep: BaseStep{
 			StepType: BeforeDamage,
 			metadata: map[string]interface{}{
-				"Source": damage.Source.Name,
-				"Target": damage.Target.Name,
-				"Amount": damage.Amount,
+				"Source":    entityName(damage.Source),
+				"Target":    damage.Target.Name,
+				"Amount":    damage.Amount,
+				"Rolls":     rollSummaries(damage.Rolls),
+				"Discarded": rollSummaries(damage.Discarded),
 			},
 		},
 		Damage: damage,
 	}
 }
 
+func rollSummaries(records []dice.RollRecord) []string {
+	summaries := make([]string, len(records))
+	for i, r := range records {
+		summaries[i] = r.String()
+	}
+	return summaries
+}
+
 func NewAfterDamageStep(damage *Damage) AfterDamageStep {
 	return AfterDamageStep{
 		BaseStep: BaseStep{
 			StepType: AfterDamage,
 			metadata: map[string]interface{}{
-				"Source":  damage.Source.Name,
-				"Target":  damage.Target.Name,
-				"Amount":  damage.Amount,
-				"Blocked": damage.Blocked,
-				"Taken":   damage.Taken,
+				"Source":      entityName(damage.Source),
+				"Target":      damage.Target.Name,
+				"Amount":      damage.Amount,
+				"Blocked":     damage.Blocked,
+				"Taken":       damage.Taken,
+				"Adjustments": damage.Adjustments,
 			},
 		},
 		Damage: damage,
@@ -61,51 +158,236 @@ func NewAfterDamageStep(damage *Damage) AfterDamageStep {
 }
 
 func Deal(gs *GameState, damage Damage) {
-	executeStep(gs, NewBeforeDamageStep(&damage), fmt.Sprintf("%s is about to deal damage to %s.", damage.Source.Name, damage.Target.Name))
-	totalDamage := 0
-	for _, dr := range damage.Amount {
-		dealDamage := dr.Amount - damage.Blocked
-		if dealDamage < 0 {
-			dealDamage = 0
-		}
-		totalDamage += dealDamage
-		applyDamage(damage, dealDamage)
+	executeStep(gs, NewBeforeDamageStep(&damage), fmt.Sprintf("%s is about to deal damage to %s.", entityName(damage.Source), damage.Target.Name))
+	wasUp := damage.Target.HP > 0
+	damage.Target.adjustDamage(&damage)
+	for _, a := range damage.Adjustments {
+		gs.Printf("%s's %s %s changes %s damage by %d.\n", damage.Target.Name, displayName(string(a.Against)),
+			displayName(string(a.Kind)), displayName(string(a.Type)), a.Value)
 	}
+	// Blocking reduces the total damage once, not each type of it
+	totalDamage := max(0, sumDamage(damage.Amount)-damage.Blocked)
+	applyDamage(gs, damage, totalDamage)
 	damage.Taken = totalDamage
+	if damage.BlockedBy != nil {
+		gs.damageShield(damage.Target, damage.BlockedBy, totalDamage)
+	}
+	if totalDamage > 0 {
+		gs.knockOut(damage.Target, wasUp, damage.Critical)
+	}
+	gs.applyPersistentDamage(damage)
 
-	executeStep(gs, NewAfterDamageStep(&damage), fmt.Sprintf("%s dealt %d damage to %s.", damage.Source.Name, damage.Taken, damage.Target.Name))
+	executeStep(gs, NewAfterDamageStep(&damage), fmt.Sprintf("%s dealt %d damage to %s.", entityName(damage.Source), damage.Taken, damage.Target.Name))
 }
 
-func applyDamage(damage Damage, totalDamage int) {
+func applyDamage(gs *GameState, damage Damage, totalDamage int) {
 	damage.Target.TakeDamage(totalDamage)
+	gs.Printf("%s takes %d damage! Remaining HP: %d\n", damage.Target.Name, totalDamage, damage.Target.HP)
 }
 
 type DamageType string
 
 const (
+	// Physical
 	Bludgeoning DamageType = "BLUDGEONING"
 	Piercing    DamageType = "PIERCING"
 	Slashing    DamageType = "SLASHING"
+	Bleed       DamageType = "BLEED"
+
+	// Energy
+	Acid        DamageType = "ACID"
+	Cold        DamageType = "COLD"
+	Electricity DamageType = "ELECTRICITY"
+	Fire        DamageType = "FIRE"
+	Sonic       DamageType = "SONIC"
+	Force       DamageType = "FORCE"
+	Vitality    DamageType = "VITALITY"
+	Void        DamageType = "VOID"
+
+	Mental DamageType = "MENTAL"
+	Poison DamageType = "POISON"
+	Spirit DamageType = "SPIRIT"
 )
 
+// DamageTypes lists every damage type the engine knows about
+var DamageTypes = []DamageType{
+	Bludgeoning, Piercing, Slashing, Bleed,
+	Acid, Cold, Electricity, Fire, Sonic, Force, Vitality, Void,
+	Mental, Poison, Spirit,
+}
+
+// ParseDamageType looks up a damage type by name, ignoring case
+func ParseDamageType(name string) (DamageType, error) {
+	for _, t := range DamageTypes {
+		if strings.EqualFold(string(t), name) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown damage type %q", name)
+}
+
 type DamageRoll struct {
-	Die   int
-	Count int
-	Bonus int
-	Type  DamageType
+	Die        int
+	Count      int
+	Bonus      int
+	Type       DamageType
+	Keep       int  // Number of dice kept; zero keeps them all
+	KeepLowest bool // Keep the lowest dice instead of the highest
+	Persistent bool // Dealt at the end of each of the target's turns rather than on the hit
+	Precision  bool // Precision damage, e.g. sneak attack, dealt as Type but ignored by creatures immune to precision
 }
 
-func (dr DamageRoll) Roll() DamageAmount {
-	amount := dr.Bonus
-	for i := 0; i < dr.Count; i++ {
-		amount += dice.Roll(dr.Die)
+// Roll rolls the damage and returns both the amount and a record of every die
+func (dr DamageRoll) Roll(src dice.Source) (DamageAmount, dice.RollRecord) {
+	record := dice.RollRecord{Expression: dr.String()}
+	if dr.Count > 0 {
+		record.AddDice(src, dr.Count, dr.Die, dr.Keep, dr.KeepLowest)
 	}
-	return DamageAmount{Amount: amount, Type: dr.Type}
+	record.AddModifier("", dr.Bonus)
+	amount := DamageAmount{Amount: record.Total, Type: dr.Type}
+	if dr.Precision {
+		amount.Precision = record.Total
+	}
+	return amount, record
+}
+
+// String renders the roll as a dice expression, e.g. "1d8+3 slashing"
+func (dr DamageRoll) String() string {
+	var sb strings.Builder
+	if dr.Count > 0 {
+		fmt.Fprintf(&sb, "%dd%d", dr.Count, dr.Die)
+		if dr.Keep > 0 {
+			if dr.KeepLowest {
+				fmt.Fprintf(&sb, "kl%d", dr.Keep)
+			} else {
+				fmt.Fprintf(&sb, "kh%d", dr.Keep)
+			}
+		}
+	}
+	if dr.Bonus != 0 || dr.Count == 0 {
+		if dr.Bonus >= 0 && dr.Count > 0 {
+			sb.WriteString("+")
+		}
+		fmt.Fprintf(&sb, "%d", dr.Bonus)
+	}
+	sb.WriteString(" ")
+	if dr.Persistent {
+		sb.WriteString("persistent ")
+	}
+	if dr.Precision {
+		sb.WriteString("precision ")
+	}
+	sb.WriteString(strings.ToLower(string(dr.Type)))
+	return sb.String()
+}
+
+// ParseDamage builds damage rolls from an expression such as "1d8+3 slashing"
+// or "2d6+1d4 fire+3". Each group of dice becomes its own DamageRoll, and flat
+// modifiers are added to the first roll of the same damage type. Damage
+// labelled "persistent", e.g. "1d6 persistent fire", is persistent damage, and
+// damage labelled "precision", e.g. "1d6 precision piercing", is precision damage.
+func ParseDamage(expression string) ([]DamageRoll, error) {
+	expr, err := dice.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolls []DamageRoll
+	flat := map[damageKind]int{}
+	for _, term := range expr.Terms {
+		if term.Label == "" {
+			return nil, fmt.Errorf("damage expression %q has no damage type", expression)
+		}
+		kind, err := parseDamageLabel(term.Label)
+		if err != nil {
+			return nil, fmt.Errorf("damage expression %q: %w", expression, err)
+		}
+		if !term.IsDice() {
+			flat[kind] += term.Modifier()
+			continue
+		}
+		if term.Sign < 0 {
+			return nil, fmt.Errorf("damage expression %q: dice cannot be subtracted from damage", expression)
+		}
+		rolls = append(rolls, DamageRoll{
+			Die:        term.Sides,
+			Count:      term.Count,
+			Type:       kind.Type,
+			Keep:       term.Keep,
+			KeepLowest: term.KeepLowest,
+			Persistent: kind.Persistent,
+			Precision:  kind.Precision,
+		})
+	}
+
+	// Fold flat modifiers into the first roll of their type, keeping the order they appeared in
+	for _, term := range expr.Terms {
+		kind, _ := parseDamageLabel(term.Label)
+		bonus, ok := flat[kind]
+		if !ok {
+			continue
+		}
+		delete(flat, kind)
+		added := false
+		for i := range rolls {
+			if rolls[i].kind() == kind {
+				rolls[i].Bonus += bonus
+				added = true
+				break
+			}
+		}
+		if !added {
+			rolls = append(rolls, DamageRoll{Bonus: bonus, Type: kind.Type, Persistent: kind.Persistent, Precision: kind.Precision})
+		}
+	}
+	return rolls, nil
+}
+
+// damageKind is what a damage label says about the damage
+type damageKind struct {
+	Type       DamageType
+	Persistent bool
+	Precision  bool
+}
+
+func (dr DamageRoll) kind() damageKind {
+	return damageKind{Type: dr.Type, Persistent: dr.Persistent, Precision: dr.Precision}
+}
+
+// parseDamageLabel reads a damage label such as "fire", "persistent bleed" or
+// "precision piercing"
+func parseDamageLabel(label string) (damageKind, error) {
+	fields := strings.Fields(label)
+	kind := damageKind{}
+	if len(fields) == 2 {
+		switch strings.ToLower(fields[0]) {
+		case "persistent":
+			kind.Persistent = true
+			label = fields[1]
+		case "precision":
+			kind.Precision = true
+			label = fields[1]
+		}
+	}
+	damageType, err := ParseDamageType(label)
+	kind.Type = damageType
+	return kind, err
+}
+
+// MustParseDamage is like ParseDamage but panics on error. It is intended for
+// hard-coded content such as bestiary entries.
+func MustParseDamage(expression string) []DamageRoll {
+	rolls, err := ParseDamage(expression)
+	if err != nil {
+		panic(err)
+	}
+	return rolls
 }
 
 type DamageAmount struct {
-	Amount int
-	Type   DamageType
+	Amount    int
+	Type      DamageType
+	Precision int // How much of Amount is precision damage
 }
 
 type BaseAttack struct {
@@ -113,11 +395,60 @@ type BaseAttack struct {
 	Bonus  int
 }
 
-func (ba BaseAttack) RollDamage() map[DamageType]DamageAmount {
+func (ba BaseAttack) RollDamage(src dice.Source) (map[DamageType]DamageAmount, []dice.RollRecord) {
 	damage := map[DamageType]DamageAmount{}
+	var records []dice.RollRecord
 	for _, dr := range ba.Damage {
-		amount := dr.Roll()
+		if dr.Persistent {
+			continue // Persistent damage is rolled each time it is taken
+		}
+		amount, record := dr.Roll(src)
+		records = append(records, record)
+		// Several rolls can share a type, e.g. "2d6+1d4 fire"
+		amount.Amount += damage[amount.Type].Amount
+		amount.Precision += damage[amount.Type].Precision
 		damage[amount.Type] = amount
 	}
-	return damage
+	return damage, records
+}
+
+// RollDamageWithFortune rolls the attack's damage, rolling all of it a second
+// time under fortune or misfortune and keeping the higher or lower total
+func (ba BaseAttack) RollDamageWithFortune(src dice.Source, fortune dice.Fortune) (map[DamageType]DamageAmount, []dice.RollRecord, []dice.RollRecord) {
+	amount, records := ba.RollDamage(src)
+	if fortune == dice.NoFortune {
+		return amount, records, nil
+	}
+	otherAmount, otherRecords := ba.RollDamage(src)
+	first, second := sumDamage(amount), sumDamage(otherAmount)
+	if (fortune == dice.FortuneRoll && second > first) || (fortune == dice.MisfortuneRoll && second < first) {
+		amount, otherAmount = otherAmount, amount
+		records, otherRecords = otherRecords, records
+	}
+	for i := range records {
+		records[i].Fortune = fortune
+	}
+	return amount, records, otherRecords
+}
+
+func sumDamage(amount map[DamageType]DamageAmount) int {
+	total := 0
+	for _, a := range amount {
+		total += a.Amount
+	}
+	return total
+}
+
+// ParseBaseAttack builds an attack from a check expression such as "1d20+7"
+// and a damage expression such as "1d8+3 slashing"
+func ParseBaseAttack(check, damage string) (BaseAttack, error) {
+	parsedCheck, err := dice.ParseCheck(check)
+	if err != nil {
+		return BaseAttack{}, err
+	}
+	rolls, err := ParseDamage(damage)
+	if err != nil {
+		return BaseAttack{}, err
+	}
+	return BaseAttack{Damage: rolls, Bonus: parsedCheck.Total()}, nil
 }
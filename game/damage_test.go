@@ -0,0 +1,129 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func damageOf(amounts map[DamageType]int) Damage {
+	amount := map[DamageType]DamageAmount{}
+	for t, a := range amounts {
+		amount[t] = DamageAmount{Amount: a, Type: t}
+	}
+	return Damage{Amount: amount}
+}
+
+func amountsOf(d Damage) map[DamageType]int {
+	amounts := map[DamageType]int{}
+	for t, a := range d.Amount {
+		amounts[t] = a.Amount
+	}
+	return amounts
+}
+
+func TestHalve(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount map[DamageType]int
+		want   map[DamageType]int
+	}{
+		{"single even", map[DamageType]int{Fire: 8}, map[DamageType]int{Fire: 4}},
+		{"single odd", map[DamageType]int{Fire: 7}, map[DamageType]int{Fire: 3}},
+		{"two odd", map[DamageType]int{Fire: 3, Cold: 3}, map[DamageType]int{Cold: 2, Fire: 1}},
+		{"three odd", map[DamageType]int{Fire: 1, Cold: 1, Acid: 1}, map[DamageType]int{Acid: 1, Cold: 0, Fire: 0}},
+		{"odd and even", map[DamageType]int{Slashing: 5, Fire: 4}, map[DamageType]int{Fire: 2, Slashing: 2}},
+		{"mixed odd and even", map[DamageType]int{Slashing: 5, Fire: 3, Cold: 2}, map[DamageType]int{Cold: 1, Fire: 2, Slashing: 2}},
+	}
+	for _, tt := range tests {
+		damage := damageOf(tt.amount)
+		halved := damage.Halve()
+		if got := amountsOf(halved); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Halve() = %v, want %v", tt.name, got, tt.want)
+		}
+		total := 0
+		for _, a := range tt.amount {
+			total += a
+		}
+		if got := totalDamage(halved); got != total/2 {
+			t.Errorf("%s: Halve() totals %d, want %d", tt.name, got, total/2)
+		}
+		if got := amountsOf(damage); !reflect.DeepEqual(got, tt.amount) {
+			t.Errorf("%s: Halve() changed the original damage to %v", tt.name, got)
+		}
+	}
+}
+
+func TestDoubleLeavesOriginal(t *testing.T) {
+	damage := damageOf(map[DamageType]int{Fire: 3, Cold: 2})
+	doubled := damage.Double()
+	if got, want := amountsOf(doubled), map[DamageType]int{Fire: 6, Cold: 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Double() = %v, want %v", got, want)
+	}
+	if !doubled.Critical || damage.Critical {
+		t.Errorf("Double() should mark only the copy as critical")
+	}
+	if got, want := amountsOf(damage), map[DamageType]int{Fire: 3, Cold: 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Double() changed the original damage to %v", got)
+	}
+}
+
+func totalDamage(d Damage) int {
+	total := 0
+	for _, a := range d.Amount {
+		total += a.Amount
+	}
+	return total
+}
+
+func TestParseDamagePrecision(t *testing.T) {
+	rolls, err := ParseDamage("1d6 precision piercing+1d8+2 piercing")
+	if err != nil {
+		t.Fatalf("ParseDamage returned error: %v", err)
+	}
+	want := []DamageRoll{
+		{Die: 6, Count: 1, Type: Piercing, Precision: true},
+		{Die: 8, Count: 1, Bonus: 2, Type: Piercing},
+	}
+	if !reflect.DeepEqual(rolls, want) {
+		t.Errorf("ParseDamage = %+v, want %+v", rolls, want)
+	}
+	if _, err := ParseDamage("1d6 precision"); err == nil {
+		t.Errorf("ParseDamage(%q) succeeded, want an error as precision is not a damage type", "1d6 precision")
+	}
+}
+
+func TestPrecisionDamage(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(e *Entity)
+		want     int
+		adjusted []DamageType
+	}{
+		{"no defences", func(e *Entity) {}, 8, nil},
+		{"physical resistance covers precision", func(e *Entity) { e.SetResistance(PhysicalDamage, 7) }, 1, []DamageType{PhysicalDamage}},
+		{"piercing weakness", func(e *Entity) { e.SetWeakness(Piercing, 2) }, 10, []DamageType{Piercing}},
+		{"immune to precision", func(e *Entity) { e.AddImmunity(PrecisionDamage) }, 6, []DamageType{PrecisionDamage}},
+		{"immune to precision and resistant", func(e *Entity) {
+			e.AddImmunity(PrecisionDamage)
+			e.SetResistance(Piercing, 2)
+		}, 4, []DamageType{PrecisionDamage, Piercing}},
+	}
+	for _, tt := range tests {
+		target := NewEntity("Target", 30, 15, BadGuys)
+		tt.setup(target)
+		damage := Damage{Target: target, Amount: map[DamageType]DamageAmount{
+			Piercing: {Amount: 8, Type: Piercing, Precision: 2},
+		}}
+		target.adjustDamage(&damage)
+		if got := totalDamage(damage); got != tt.want {
+			t.Errorf("%s: took %d damage, want %d", tt.name, got, tt.want)
+		}
+		var adjusted []DamageType
+		for _, a := range damage.Adjustments {
+			adjusted = append(adjusted, a.Against)
+		}
+		if !reflect.DeepEqual(adjusted, tt.adjusted) {
+			t.Errorf("%s: adjusted by %v, want %v", tt.name, adjusted, tt.adjusted)
+		}
+	}
+}
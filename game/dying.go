@@ -0,0 +1,116 @@
+package game
+
+const (
+	Dying       ConditionName = "DYING"
+	Wounded     ConditionName = "WOUNDED"
+	Doomed      ConditionName = "DOOMED"
+	Unconscious ConditionName = "UNCONSCIOUS"
+)
+
+// LifeState summarises whether an entity is up, knocked out or dead
+type LifeState string
+
+const (
+	Conscious  LifeState = "CONSCIOUS"
+	KnockedOut LifeState = "UNCONSCIOUS" // At 0 HP but no longer dying
+	DyingState LifeState = "DYING"
+	DeadState  LifeState = "DEAD"
+)
+
+// LifeState reports whether the entity is conscious, dying, unconscious or dead
+func (e *Entity) LifeState() LifeState {
+	switch {
+	case !e.IsAlive():
+		return DeadState
+	case e.HasCondition(Dying):
+		return DyingState
+	case e.HasCondition(Unconscious) || e.HP <= 0:
+		return KnockedOut
+	default:
+		return Conscious
+	}
+}
+
+// IsConscious reports whether the entity is alive and able to act
+func (e *Entity) IsConscious() bool {
+	return e.LifeState() == Conscious
+}
+
+// deathThreshold is the dying value at which the entity dies
+func (e *Entity) deathThreshold() int {
+	return 4 - e.ConditionValue(Doomed)
+}
+
+// knockOut handles an entity taking damage that leaves it at 0 HP. Creatures
+// that die at 0 HP die; others start dying, or get closer to death if they
+// were already dying. Critical hits and critically failed saves count double.
+func (gs *GameState) knockOut(e *Entity, wasUp bool, critical bool) {
+	if e.HP > 0 || e.Dead {
+		return
+	}
+	// Nobody at 0 HP can act, even if it drops during its own turn
+	e.ActionsRemaining = 0
+
+	if e.DiesAtZeroHP {
+		if wasUp {
+			gs.Printf("%s dies.\n", e.Name)
+		}
+		return
+	}
+
+	increase := 1
+	if critical {
+		increase = 2
+	}
+	if wasUp || !e.HasCondition(Dying) {
+		// Falling unconscious adds the wounded value to the new dying value
+		dying := increase + e.ConditionValue(Wounded)
+		e.AddCondition(Condition{Name: Unconscious})
+		e.setConditionValue(Dying, dying)
+		gs.Printf("%s falls unconscious and is dying %d.\n", e.Name, dying)
+	} else {
+		e.setConditionValue(Dying, e.ConditionValue(Dying)+increase)
+		gs.Printf("%s is now dying %d.\n", e.Name, e.ConditionValue(Dying))
+	}
+	gs.checkDeath(e)
+}
+
+// checkDeath kills the entity once its dying value reaches its death threshold
+func (gs *GameState) checkDeath(e *Entity) {
+	if e.HasCondition(Dying) && e.ConditionValue(Dying) >= e.deathThreshold() {
+		e.Dead = true
+		e.RemoveCondition(Dying)
+		gs.Printf("%s dies.\n", e.Name)
+	}
+}
+
+// loseDying ends the dying condition, increasing the entity's wounded value by 1
+func (gs *GameState) loseDying(e *Entity) {
+	if !e.HasCondition(Dying) {
+		return
+	}
+	e.RemoveCondition(Dying)
+	wounded := e.ConditionValue(Wounded) + 1
+	e.setConditionValue(Wounded, wounded)
+	gs.Printf("%s is no longer dying and is now wounded %d.\n", e.Name, wounded)
+}
+
+// recoveryCheck is the flat check a dying entity attempts at the start of its
+// turn against DC 10 + its dying value
+func (gs *GameState) recoveryCheck(e *Entity) {
+	dying := e.ConditionValue(Dying)
+	if dying == 0 {
+		return
+	}
+	check := ResolveCheck(gs, &Check{Roller: e, Name: "Recovery", DC: 10 + dying})
+	change := map[DegreeOfSuccess]int{CriticalSuccess: -2, Success: -1, Failure: 1, CriticalFailure: 2}[check.Degree]
+
+	dying += change
+	if dying <= 0 {
+		gs.loseDying(e)
+		return
+	}
+	e.setConditionValue(Dying, dying)
+	gs.Printf("%s is now dying %d.\n", e.Name, dying)
+	gs.checkDeath(e)
+}
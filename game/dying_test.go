@@ -0,0 +1,168 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+	"testing"
+)
+
+// newDyingTest puts a hero who falls unconscious at 0 HP next to an ogre
+// whose club hits at +10 for 1d6 bludgeoning
+func newDyingTest(t *testing.T) (*GameState, *Entity, *Entity) {
+	t.Helper()
+	hero := NewEntity("Hero", 5, 15, GoodGuys)
+	hero.DiesAtZeroHP = false
+	ogre := NewEntity("Ogre", 30, 15, BadGuys)
+	gs := newTestGame([]Spawn{NewSpawn(hero, 0, 0), NewSpawn(ogre, 1, 0)}, dice.NewSeededSource(1))
+	return gs, hero, ogre
+}
+
+// clubHero has the ogre hit the hero with the given d20 and no multiple
+// attack penalty, dealing 6 damage or 12 on a critical hit
+func clubHero(t *testing.T, gs *GameState, ogre, hero *Entity, d20 int) {
+	t.Helper()
+	club, err := ParseBaseAttack("1d20+10", "1d6 bludgeoning")
+	if err != nil {
+		t.Fatalf("ParseBaseAttack returned error: %v", err)
+	}
+	ogre.MapCounter = 0
+	src := scriptDice(gs, dice.ScriptedRoll{Sides: 20, Value: d20}, dice.ScriptedRoll{Sides: 6, Value: 6})
+	PerformAttack(gs, club, ogre, hero)
+	if src.Remaining() != 0 {
+		t.Fatalf("the attack left %d scripted rolls unused", src.Remaining())
+	}
+}
+
+const (
+	clubHit  = 10 // 20 against AC 15
+	clubCrit = 15 // 25 against AC 15
+
+	// An unconscious hero is off-guard and takes a -4 status penalty to AC
+	clubHitDown  = 5  // 15 against AC 9
+	clubCritDown = 10 // 20 against AC 9
+)
+
+func TestKnockOut(t *testing.T) {
+	tests := []struct {
+		name            string
+		wounded, doomed int
+		d20             int
+		dying           int
+		dead            bool
+	}{
+		{"hit", 0, 0, clubHit, 1, false},
+		{"critical hit", 0, 0, clubCrit, 2, false},
+		{"wounded adds to dying", 1, 0, clubHit, 2, false},
+		{"wounded adds to a critical hit", 1, 0, clubCrit, 3, false},
+		{"wounded 2 and a critical hit kill", 2, 0, clubCrit, 0, true},
+		{"doomed lowers the threshold", 1, 1, clubCrit, 0, true},
+		{"doomed short of the threshold", 0, 1, clubCrit, 2, false},
+	}
+	for _, tt := range tests {
+		gs, hero, ogre := newDyingTest(t)
+		if tt.wounded > 0 {
+			hero.AddCondition(Condition{Name: Wounded, Value: tt.wounded})
+		}
+		if tt.doomed > 0 {
+			hero.AddCondition(Condition{Name: Doomed, Value: tt.doomed})
+		}
+		clubHero(t, gs, ogre, hero, tt.d20)
+		if hero.Dead != tt.dead {
+			t.Errorf("%s: dead = %v, want %v", tt.name, hero.Dead, tt.dead)
+		}
+		if got := hero.ConditionValue(Dying); got != tt.dying {
+			t.Errorf("%s: dying %d, want %d", tt.name, got, tt.dying)
+		}
+		if !tt.dead && !hero.HasCondition(Unconscious) {
+			t.Errorf("%s: hero is not unconscious", tt.name)
+		}
+	}
+}
+
+func TestDamageWhileDying(t *testing.T) {
+	tests := []struct {
+		name  string
+		d20   int
+		dying int
+		dead  bool
+	}{
+		{"hit", clubHitDown, 2, false},
+		{"critical hit", clubCritDown, 3, false},
+	}
+	for _, tt := range tests {
+		gs, hero, ogre := newDyingTest(t)
+		clubHero(t, gs, ogre, hero, clubHit)
+		hero.AddCondition(Condition{Name: Wounded, Value: 1})
+		clubHero(t, gs, ogre, hero, tt.d20)
+		// Wounded only counts when falling unconscious, not while already dying
+		if got := hero.ConditionValue(Dying); got != tt.dying || hero.Dead != tt.dead {
+			t.Errorf("%s: dying %d, dead %v, want dying %d, dead %v", tt.name, got, hero.Dead, tt.dying, tt.dead)
+		}
+	}
+}
+
+func TestRecoveryCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		dying   int
+		doomed  int
+		d20     int
+		want    int // Dying value after the check
+		wounded int
+		dead    bool
+	}{
+		{"success against DC 11", 1, 0, 11, 0, 1, false},
+		{"failure against DC 11", 1, 0, 10, 2, 0, false},
+		{"natural 20 is a critical success", 2, 0, 20, 0, 1, false},
+		{"success against DC 12", 2, 0, 12, 1, 0, false},
+		{"failure against DC 12", 2, 0, 11, 3, 0, false},
+		{"natural 1 is a critical failure", 1, 0, 1, 3, 0, false},
+		{"critical failure at dying 2 kills", 2, 0, 2, 0, 0, true},
+		{"failure at dying 3 kills", 3, 0, 5, 0, 0, true},
+		{"doomed 1 dies at dying 3", 2, 1, 5, 0, 0, true},
+		{"doomed 2 dies at dying 2", 1, 2, 5, 0, 0, true},
+	}
+	for _, tt := range tests {
+		gs, hero, _ := newDyingTest(t)
+		hero.HP = 0
+		hero.AddCondition(Condition{Name: Unconscious})
+		hero.AddCondition(Condition{Name: Dying, Value: tt.dying})
+		if tt.doomed > 0 {
+			hero.AddCondition(Condition{Name: Doomed, Value: tt.doomed})
+		}
+		scriptDice(gs, dice.ScriptedRoll{Sides: 20, Value: tt.d20})
+		gs.recoveryCheck(hero)
+		if got := hero.ConditionValue(Dying); got != tt.want {
+			t.Errorf("%s: dying %d, want %d", tt.name, got, tt.want)
+		}
+		if got := hero.ConditionValue(Wounded); got != tt.wounded {
+			t.Errorf("%s: wounded %d, want %d", tt.name, got, tt.wounded)
+		}
+		if hero.Dead != tt.dead {
+			t.Errorf("%s: dead = %v, want %v", tt.name, hero.Dead, tt.dead)
+		}
+	}
+}
+
+func TestWoundedStacks(t *testing.T) {
+	gs, hero, ogre := newDyingTest(t)
+	for round, wantDying := range []int{1, 2} {
+		hero.HP = 5
+		hero.RemoveCondition(Unconscious)
+		clubHero(t, gs, ogre, hero, clubHit)
+		if got := hero.ConditionValue(Dying); got != wantDying {
+			t.Fatalf("knock-out %d: dying %d, want %d", round+1, got, wantDying)
+		}
+		// A critical success recovers, adding 1 to wounded
+		scriptDice(gs, dice.ScriptedRoll{Sides: 20, Value: 20})
+		gs.recoveryCheck(hero)
+		if got := hero.ConditionValue(Wounded); got != round+1 {
+			t.Fatalf("recovery %d: wounded %d, want %d", round+1, got, round+1)
+		}
+	}
+	hero.HP = 5
+	hero.RemoveCondition(Unconscious)
+	clubHero(t, gs, ogre, hero, clubCrit)
+	if !hero.Dead {
+		t.Errorf("wounded 2 hero survived a critical hit on dying %d", hero.ConditionValue(Dying))
+	}
+}
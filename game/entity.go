@@ -10,6 +10,7 @@ type Entity struct {
 	Name               string
 	HP                 int
 	MaxHP              int  // Maximum HP for tracking
+	TempHP             int  // Temporary HP, lost before real HP
 	AC                 int
 	Initiative         int
 	ActionsRemaining   int
@@ -18,6 +19,29 @@ type Entity struct {
 	Controller         Controller
 	ActionCards        []*ActionCard
 	Faction            Faction
+	FortuneEffects     []FortuneEffect
+	Saves              map[SaveType]int
+	Skills             map[Skill]int
+	Perception         int
+	Level              int
+	Attributes         *Attributes // Attribute modifiers; nil for entities whose statistics are set directly
+	Proficiencies      map[Statistic]Proficiency
+	ItemBonuses        map[Statistic]int
+	KeyAttribute       AttributeName // The attribute class DC is based on
+	Conditions         []Condition
+	DiesAtZeroHP       bool // Dies outright at 0 HP instead of falling unconscious and dying
+	Resistances        map[DamageType]int // Keyed by damage type or category, e.g. FIRE or PHYSICAL
+	Weaknesses         map[DamageType]int
+	Immunities         map[DamageType]bool
+	Weapons            []WieldedWeapon // Weapons in hand, each giving the entity a Strike
+	Armor              *Armor          // Worn armor; nil when unarmored
+	Shield             *Shield         // Held shield; nil without one
+	Ammunition         map[string]int  // Ammunition carried, by kind, e.g. "Arrows"
+	Speed              int             // Land Speed in feet before armor; zero uses DefaultSpeed
+	Spellcasting       *Spellcasting   // Spells the entity can cast; nil for non-casters
+	Dead               bool
+
+	attacksThisTurn []turnAttack // This turn's attacks, for the sweep and forceful traits
 }
 
 func (e *Entity) AddActionCard(card *ActionCard) {
@@ -31,6 +55,17 @@ const (
 	BadGuys
 )
 
+func (f Faction) String() string {
+	switch f {
+	case GoodGuys:
+		return "GoodGuys"
+	case BadGuys:
+		return "BadGuys"
+	default:
+		return fmt.Sprintf("Faction%d", int(f))
+	}
+}
+
 // NewEntity creates a new Entity instance
 func NewEntity(name string, hp, ac int, faction Faction) *Entity {
 	return &Entity{
@@ -43,12 +78,18 @@ func NewEntity(name string, hp, ac int, faction Faction) *Entity {
 		ReactionsRemaining: 1,
 		Controller:         NewAIController(),
 		Faction:            faction,
+		Speed:              DefaultSpeed,
+		DiesAtZeroHP:       true,
 	}
 }
 
-// IsAlive checks if the entity is still alive
+// IsAlive checks if the entity is still alive. Entities that don't die at 0 HP
+// stay alive while dying.
 func (e *Entity) IsAlive() bool {
-	return e.HP > 0
+	if e.Dead {
+		return false
+	}
+	return e.HP > 0 || !e.DiesAtZeroHP
 }
 
 // SetController assigns a controllerhttp to the entity
@@ -56,13 +97,15 @@ func (e *Entity) SetController(controller Controller) {
 	e.Controller = controller
 }
 
-// TakeDamage reduces the entity's HP by a given amount
+// TakeDamage reduces the entity's HP by a given amount, using up temporary HP first
 func (e *Entity) TakeDamage(damage int) {
+	absorbed := min(damage, e.TempHP)
+	e.TempHP -= absorbed
+	damage -= absorbed
 	e.HP -= damage
 	if e.HP < 0 {
 		e.HP = 0
 	}
-	fmt.Printf("%s takes %d damage! Remaining HP: %d\n", e.Name, damage, e.HP)
 }
 
 // RollInitiative sets the entity's initiative value
@@ -75,6 +118,10 @@ func (e *Entity) ResetTurnResources() {
 	e.ActionsRemaining = 3
 	e.ReactionsRemaining = 1
 	e.MapCounter = 0
+	e.attacksThisTurn = nil
+	if e.Shield != nil {
+		e.Shield.Raised = false
+	}
 }
 
 // SpendAction attempts to consume an action
@@ -107,7 +154,7 @@ func (e *Entity) GetNextLivingTarget(entities []*Entity) *Entity {
 
 // String provides a readable string representation of the entity
 func (e *Entity) String() string {
-	return fmt.Sprintf("%s (HP: %d, AC: %d, Actions: %d, Reactions: %d)", e.Name, e.HP, e.AC, e.ActionsRemaining, e.ReactionsRemaining)
+	return fmt.Sprintf("%s (HP: %d, AC: %d, Actions: %d, Reactions: %d)", e.Name, e.HP, e.ArmorClass(), e.ActionsRemaining, e.ReactionsRemaining)
 }
 
 func findEntityByID(entities []*Entity, id uuid.UUID) *Entity {
@@ -0,0 +1,87 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+)
+
+// RollKind identifies which rolls a fortune effect applies to
+type RollKind string
+
+const (
+	CheckRollKind  RollKind = "CHECK"
+	DamageRollKind RollKind = "DAMAGE"
+)
+
+// FortuneEffect makes an entity roll twice and keep the higher (fortune) or
+// lower (misfortune) result, e.g. a hero point reroll or a misfortune curse
+type FortuneEffect struct {
+	Name    string
+	Fortune dice.Fortune
+	Rolls   RollKind
+	Uses    int // Number of rolls the effect lasts for; zero lasts until removed
+}
+
+// AddFortuneEffect gives the entity a fortune or misfortune effect
+func (e *Entity) AddFortuneEffect(effect FortuneEffect) {
+	e.FortuneEffects = append(e.FortuneEffects, effect)
+}
+
+// RemoveFortuneEffect removes every fortune effect with the given name
+func (e *Entity) RemoveFortuneEffect(name string) {
+	kept := e.FortuneEffects[:0]
+	for _, effect := range e.FortuneEffects {
+		if effect.Name != name {
+			kept = append(kept, effect)
+		}
+	}
+	e.FortuneEffects = kept
+}
+
+// useFortune works out how the entity's next roll of the given kind is made.
+// Effects with limited uses are spent even when fortune and misfortune cancel out.
+func (e *Entity) useFortune(kind RollKind) dice.Fortune {
+	fortune, misfortune := false, false
+	kept := e.FortuneEffects[:0]
+	for _, effect := range e.FortuneEffects {
+		if effect.Rolls == kind {
+			switch effect.Fortune {
+			case dice.FortuneRoll:
+				fortune = true
+			case dice.MisfortuneRoll:
+				misfortune = true
+			}
+			if effect.Uses > 0 {
+				effect.Uses--
+				if effect.Uses == 0 {
+					continue
+				}
+			}
+		}
+		kept = append(kept, effect)
+	}
+	e.FortuneEffects = kept
+	return dice.ResolveFortune(fortune, misfortune)
+}
+
+// rollD20 rolls a check for the entity, honouring its fortune effects
+func rollD20(gs *GameState, roller *Entity, modifiers []dice.Modifier) dice.RollRecord {
+	defer gs.rollAs(roller)()
+	return dice.RollWithFortune(roller.useFortune(CheckRollKind), func() dice.RollRecord {
+		record := dice.RollRecord{Expression: "1d20"}
+		record.AddDice(gs.Dice, 1, 20, 0, false)
+		for _, m := range modifiers {
+			record.AddModifier(m.Source, m.Value)
+		}
+		return record
+	})
+}
+
+// d20Face returns the natural d20 result of a check record
+func d20Face(record dice.RollRecord) int {
+	for _, d := range record.Dice {
+		if d.Sides == 20 && !d.Dropped {
+			return d.Value
+		}
+	}
+	return 0
+}
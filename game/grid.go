@@ -10,6 +10,7 @@ type Grid struct {
 	Width  int
 	Height int
 	Cells  map[Position]*Entity
+	Walls  map[Position]bool // Solid squares that can't be entered and block line of effect
 }
 
 // NewGrid initializes a new grid with the given dimensions.
@@ -23,7 +24,7 @@ func NewGrid(width, height int) *Grid {
 
 // AddEntity places an entity at a specific position on the grid.
 func (g *Grid) AddEntity(pos Position, e *Entity) bool {
-	if !g.IsValidPosition(pos) || g.IsOccupied(pos) {
+	if !g.IsValidPosition(pos) || g.IsOccupied(pos) || g.IsWall(pos) {
 		return false
 	}
 	g.Cells[pos] = e
@@ -32,7 +33,7 @@ func (g *Grid) AddEntity(pos Position, e *Entity) bool {
 
 // MoveEntity moves an entity from one position to another.
 func (g *Grid) MoveEntity(from, to Position) bool {
-	if !g.IsValidPosition(to) || g.IsOccupied(to) {
+	if !g.IsValidPosition(to) || g.IsOccupied(to) || g.IsWall(to) {
 		return false
 	}
 	if entity, exists := g.Cells[from]; exists {
@@ -54,6 +55,46 @@ func (g *Grid) IsOccupied(pos Position) bool {
 	return exists
 }
 
+// AddWall makes a square solid.
+func (g *Grid) AddWall(pos Position) bool {
+	if !g.IsValidPosition(pos) || g.IsOccupied(pos) {
+		return false
+	}
+	if g.Walls == nil {
+		g.Walls = make(map[Position]bool)
+	}
+	g.Walls[pos] = true
+	return true
+}
+
+// IsWall checks if a position is a solid square.
+func (g *Grid) IsWall(pos Position) bool {
+	return g.Walls[pos]
+}
+
+// HasLineOfEffect checks that a straight line from the center of one square to the center
+// of another passes through no walls. Creatures don't block line of effect.
+func (g *Grid) HasLineOfEffect(from, to Position) bool {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	steps := max(abs(dx), abs(dy))
+	for i := 1; i < steps; i++ {
+		// Round to the nearest square along the line, as Bresenham's algorithm does
+		p := Position{X: from.X + roundDiv(dx*i, steps), Y: from.Y + roundDiv(dy*i, steps)}
+		if g.IsWall(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// roundDiv divides a by the positive b, rounding to the nearest integer.
+func roundDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b/2) / b)
+	}
+	return (a + b/2) / b
+}
+
 // IsValidPosition checks if a position is within grid bounds.
 func (g *Grid) IsValidPosition(pos Position) bool {
 	return pos.X >= 0 && pos.Y >= 0 && pos.X < g.Width && pos.Y < g.Height
@@ -195,7 +236,7 @@ func (g *Grid) FindBestMove(from Position, toward Position, maxDistance int) Pos
 		
 		for _, candidate := range candidates {
 			// Check if the position is valid and unoccupied
-			if g.IsValidPosition(candidate) && !g.IsOccupied(candidate) {
+			if g.IsValidPosition(candidate) && !g.IsOccupied(candidate) && !g.IsWall(candidate) {
 				candidateDistance := g.CalculateDistance(candidate, toward)
 				if candidateDistance < bestDistance {
 					bestPosition = candidate
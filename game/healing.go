@@ -0,0 +1,163 @@
+package game
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	dice "pf2eEngine/util"
+)
+
+// Healing restores HP to a target, up to its maximum
+type Healing struct {
+	Source *Entity // May be nil, e.g. for a potion or regeneration
+	Target *Entity
+	Amount int
+	Rolls  []dice.RollRecord
+	Healed int // HP actually restored once capped by the target's MaxHP
+}
+
+type BeforeHealStep struct {
+	BaseStep
+	Healing *Healing
+}
+
+type AfterHealStep struct {
+	BaseStep
+	Healing *Healing
+}
+
+func NewBeforeHealStep(healing *Healing) BeforeHealStep {
+	return BeforeHealStep{
+		BaseStep: BaseStep{
+			StepType: BeforeHeal,
+			metadata: map[string]interface{}{
+				"Source": entityName(healing.Source),
+				"Target": healing.Target.Name,
+				"Amount": healing.Amount,
+			},
+		},
+		Healing: healing,
+	}
+}
+
+func NewAfterHealStep(healing *Healing) AfterHealStep {
+	return AfterHealStep{
+		BaseStep: BaseStep{
+			StepType: AfterHeal,
+			metadata: map[string]interface{}{
+				"Source": entityName(healing.Source),
+				"Target": healing.Target.Name,
+				"Amount": healing.Amount,
+				"Healed": healing.Healed,
+			},
+		},
+		Healing: healing,
+	}
+}
+
+// rollHealing rolls a healing expression such as "1d8+8" from source to target
+func rollHealing(gs *GameState, source *Entity, target *Entity, expression string) (Healing, error) {
+	expr, err := dice.Parse(expression)
+	if err != nil {
+		return Healing{}, err
+	}
+	if source != nil {
+		defer gs.rollAs(source)()
+	}
+	record := expr.Roll(gs.Dice)
+	return Healing{Source: source, Target: target, Amount: record.Total, Rolls: []dice.RollRecord{record}}, nil
+}
+
+// Heal restores HP to the healing's target. Triggers on BEFORE_HEAL may change
+// the amount. A dying or unconscious target brought above 0 HP wakes up, losing
+// the dying condition and increasing its wounded value.
+func Heal(gs *GameState, healing Healing) {
+	target := healing.Target
+	executeStep(gs, NewBeforeHealStep(&healing), fmt.Sprintf("%s is about to heal %s.", entityName(healing.Source), target.Name))
+
+	if target.IsAlive() {
+		wasDown := target.HP <= 0
+		healing.Healed = max(0, min(healing.Amount, target.MaxHP-target.HP))
+		target.HP += healing.Healed
+		gs.Printf("%s regains %d HP! Current HP: %d\n", target.Name, healing.Healed, target.HP)
+
+		if wasDown && target.HP > 0 {
+			gs.loseDying(target)
+			target.RemoveCondition(Unconscious)
+			gs.Printf("%s regains consciousness.\n", target.Name)
+		}
+	}
+
+	executeStep(gs, NewAfterHealStep(&healing), fmt.Sprintf("%s healed %s for %d HP.", entityName(healing.Source), target.Name, healing.Healed))
+}
+
+// GainTempHP gives the entity temporary HP. Temporary HP doesn't stack: the
+// entity keeps whichever amount is higher.
+func (e *Entity) GainTempHP(amount int) {
+	if amount > e.TempHP {
+		e.TempHP = amount
+	}
+}
+
+// NewHealCard creates the heal spell, whose effect depends on the actions spent:
+// one action heals 1d8 to a creature within reach, two actions heal 1d8+8 to a
+// creature within 30 feet, and three actions heal 1d8 to every living creature
+// in a 30-foot emanation, allies and enemies alike. Casting it with one action
+// is a manipulate action; with more it is also a concentrate action.
+func NewHealCard() *ActionCard {
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        "Heal",
+		Type:        VariableActionCard,
+		Description: "Spend 1 action to heal a creature within reach, 2 actions to heal more at range, or 3 actions to heal every living creature in a 30-foot emanation.",
+		Traits:      []ActionTrait{ConcentrateTrait, ManipulateTrait},
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			cost, err := variableCost(params, 1, 3)
+			if err != nil {
+				return Action{}, err
+			}
+
+			var target *Entity
+			expression := "1d8"
+			traits := []ActionTrait{ConcentrateTrait, ManipulateTrait}
+			switch cost {
+			case 1, 2:
+				reach := 5
+				if cost == 1 {
+					traits = []ActionTrait{ManipulateTrait}
+				} else {
+					reach, expression = 30, "1d8+8"
+				}
+				target, err = getSingleTarget(gs, actor, []TargetCriterion{IsAlive(), Range(reach), InLineOfEffect()}, params)
+				if err != nil {
+					return Action{}, err
+				}
+			}
+
+			return Action{
+				Name:   "Heal",
+				Cost:   cost,
+				Traits: traits,
+				perform: func(gs *GameState, actor *Entity) {
+					targets := []*Entity{target}
+					if target == nil {
+						targets = nil
+						emanation := AreaTemplate{Area: Area{Shape: Emanation, Size: 30}, Origin: gs.Grid.GetEntityPosition(actor)}
+						for _, e := range PlaceArea(gs, actor, emanation) {
+							if e.IsAlive() {
+								targets = append(targets, e)
+							}
+						}
+					}
+					for _, target := range targets {
+						healing, err := rollHealing(gs, actor, target, expression)
+						if err != nil {
+							gs.Printf("%s fails to heal %s: %v\n", actor.Name, target.Name, err)
+							continue
+						}
+						Heal(gs, healing)
+					}
+				},
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+	"reflect"
+	"testing"
+)
+
+func TestHealCardTraits(t *testing.T) {
+	healer := NewEntity("Healer", 20, 15, GoodGuys)
+	ally := NewEntity("Ally", 20, 15, GoodGuys)
+	gs := newTestGame([]Spawn{NewSpawn(healer, 0, 0), NewSpawn(ally, 0, 1)}, dice.NewSeededSource(1))
+	card := NewHealCard()
+
+	tests := []struct {
+		cost int
+		want []ActionTrait
+	}{
+		{1, []ActionTrait{ManipulateTrait}},
+		{2, []ActionTrait{ConcentrateTrait, ManipulateTrait}},
+		{3, []ActionTrait{ConcentrateTrait, ManipulateTrait}},
+	}
+	for _, tt := range tests {
+		action, err := card.generate(gs, healer, map[string]interface{}{ActionCost: tt.cost, TargetID: ally.Id})
+		if err != nil {
+			t.Fatalf("%d-action Heal: %v", tt.cost, err)
+		}
+		if !reflect.DeepEqual(action.Traits, tt.want) {
+			t.Errorf("%d-action Heal has traits %v, want %v", tt.cost, action.Traits, tt.want)
+		}
+	}
+}
+
+func TestHealEmanationHealsEveryLivingCreatureInIt(t *testing.T) {
+	healer := NewEntity("Healer", 20, 15, GoodGuys)
+	ally := NewEntity("Ally", 20, 15, GoodGuys)
+	enemy := NewEntity("Enemy", 20, 15, BadGuys)
+	farAlly := NewEntity("Far Ally", 20, 15, GoodGuys)
+	gs := newTestGame([]Spawn{
+		NewSpawn(healer, 0, 0),
+		NewSpawn(enemy, 3, 0),
+		NewSpawn(ally, 0, 1),
+		NewSpawn(farAlly, 0, 7),
+	}, dice.NewSeededSource(1))
+	ally.HP, enemy.HP, farAlly.HP = 5, 10, 5
+
+	action, err := NewHealCard().generate(gs, healer, map[string]interface{}{ActionCost: 3})
+	if err != nil {
+		t.Fatalf("3-action Heal: %v", err)
+	}
+	// The emanation covers the healer, the enemy and the ally, in grid order
+	src := scriptDice(gs, dice.ScriptedRoll{Sides: 8, Value: 8}, dice.ScriptedRoll{Sides: 8, Value: 5}, dice.ScriptedRoll{Sides: 8, Value: 3})
+	ExecuteAction(gs, healer, action)
+
+	if src.Remaining() != 0 {
+		t.Errorf("%d scripted rolls left over", src.Remaining())
+	}
+	for _, tt := range []struct {
+		e    *Entity
+		want int
+	}{{healer, 20}, {enemy, 15}, {ally, 8}, {farAlly, 5}} {
+		if tt.e.HP != tt.want {
+			t.Errorf("%s has %d HP, want %d", tt.e.Name, tt.e.HP, tt.want)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package game
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	dice "pf2eEngine/util"
 	"sort"
 	"time"
@@ -23,6 +25,16 @@ type GameState struct {
 	InitialEntities     []*Entity    // Copy of initial entities for resetting
 	InitialEntityPos    map[string]Position // Initial positions of entities
 	InitialCurrentTurn  int          // Initial current turn
+	Seed                int64        // Seed of the roll source, recorded so combats can be replayed
+	Dice                dice.Source  // Source of every die rolled in this game
+	InitialDice         dice.Source  // Copy of Dice taken after initiative, for replaying from the initial state
+	Triggers            map[StepType][]Trigger // Triggers registered for this game, by step type
+	Out                 io.Writer    // Console output; set to io.Discard for headless runs
+	Round               int          // Current round, starting at 1
+	Over                bool         // Set once only one faction is left standing
+	Winner              *Faction     // The last faction standing, nil for a draw or an unfinished combat
+
+	roller *Entity // Entity making the current roll, see rollAs
 }
 
 type StepHistory struct {
@@ -44,7 +56,24 @@ type LogEntry struct {
 }
 
 // NewGameState initializes a new game state with the given entities and grid
+// using a freshly seeded roll source
 func NewGameState(spawns []Spawn, gridWidth, gridHeight int) *GameState {
+	return NewSeededGameState(spawns, gridWidth, gridHeight, dice.NewSeed())
+}
+
+// NewSeededGameState initializes a new game state whose rolls are fully determined by seed
+func NewSeededGameState(spawns []Spawn, gridWidth, gridHeight int, seed int64) *GameState {
+	return NewGameStateWithSource(spawns, gridWidth, gridHeight, dice.NewSeededSource(seed))
+}
+
+// NewGameStateWithSource initializes a new game state that takes all of its rolls,
+// including initiative, from src. If src exposes its seed, the seed is recorded.
+func NewGameStateWithSource(spawns []Spawn, gridWidth, gridHeight int, src dice.Source) *GameState {
+	return newGameState(spawns, gridWidth, gridHeight, src, os.Stdout)
+}
+
+// newGameState initializes a game that writes its console output to out
+func newGameState(spawns []Spawn, gridWidth, gridHeight int, src dice.Source, out io.Writer) *GameState {
 	entities := []*Entity{}
 	for _, spawn := range spawns {
 		entities = append(entities, spawn.Unit)
@@ -57,6 +86,12 @@ func NewGameState(spawns []Spawn, gridWidth, gridHeight int) *GameState {
 		Initiative:  entities,
 		StepHistory: &StepHistory{},
 		InitialEntityPos: make(map[string]Position), // Using ID string as key
+		Dice:        src,
+		Out:         out,
+		Round:       1,
+	}
+	if seeded, ok := src.(interface{ Seed() int64 }); ok {
+		gs.Seed = seeded.Seed()
 	}
 	
 	// Save initial positions as we place entities
@@ -71,27 +106,15 @@ func NewGameState(spawns []Spawn, gridWidth, gridHeight int) *GameState {
 	
 	// Save initial state AFTER initiative is rolled
 	gs.InitialCurrentTurn = gs.CurrentTurn
+	if cloner, ok := src.(interface{ Clone() dice.Source }); ok {
+		gs.InitialDice = cloner.Clone()
+	}
 	
 	// Create deep copies of all entities to save initial state
 	gs.InitialEntities = make([]*Entity, len(gs.Initiative))
 	for i, entity := range gs.Initiative {
-		// Deep copy each entity
-		copiedEntity := &Entity{
-			Id:                 entity.Id,
-			Name:               entity.Name,
-			HP:                 entity.HP,
-			MaxHP:              entity.HP, // Store original HP as MaxHP
-			AC:                 entity.AC,
-			ActionsRemaining:   entity.ActionsRemaining,
-			ReactionsRemaining: entity.ReactionsRemaining,
-			Faction:            entity.Faction,
-			// Deep copy action cards array
-			ActionCards:        make([]*ActionCard, len(entity.ActionCards)),
-		}
-		
-		// Copy action cards
-		copy(copiedEntity.ActionCards, entity.ActionCards)
-		
+		copiedEntity := entity.snapshot()
+		copiedEntity.MaxHP = entity.HP // Store original HP as MaxHP
 		gs.InitialEntities[i] = copiedEntity
 	}
 	
@@ -100,6 +123,7 @@ func NewGameState(spawns []Spawn, gridWidth, gridHeight int) *GameState {
 		"grid_width": gridWidth,
 		"grid_height": gridHeight,
 		"entity_count": len(entities),
+		"seed": gs.Seed,
 	})
 	
 	// Update all entities with their MaxHP
@@ -119,6 +143,9 @@ type StartTurnStep struct {
 func (gs *GameState) GetInitialState() *GameState {
 	// Create a new grid
 	initialGrid := NewGrid(gs.Grid.Width, gs.Grid.Height)
+	for pos := range gs.Grid.Walls {
+		initialGrid.AddWall(pos)
+	}
 	
 	// Create a new state
 	initialState := &GameState{
@@ -128,24 +155,16 @@ func (gs *GameState) GetInitialState() *GameState {
 		Logs:         []LogEntry{},
 		StepHistory:  &StepHistory{},
 		InitialEntityPos: gs.InitialEntityPos,
+		Seed:         gs.Seed,
+		Dice:         gs.initialDice(),
+		InitialDice:  gs.InitialDice,
+		Out:          gs.Out,
+		Round:        1,
 	}
 	
 	// Deep copy all initial entities
 	for i, entity := range gs.InitialEntities {
-		copiedEntity := &Entity{
-			Id:                 entity.Id,
-			Name:               entity.Name,
-			HP:                 entity.HP,
-			MaxHP:              entity.MaxHP,
-			AC:                 entity.AC,
-			ActionsRemaining:   entity.ActionsRemaining,
-			ReactionsRemaining: entity.ReactionsRemaining,
-			Faction:            entity.Faction,
-			ActionCards:        make([]*ActionCard, len(entity.ActionCards)),
-		}
-		
-		// Copy action cards
-		copy(copiedEntity.ActionCards, entity.ActionCards)
+		copiedEntity := entity.snapshot()
 		
 		initialState.Initiative[i] = copiedEntity
 		
@@ -161,9 +180,57 @@ func (gs *GameState) GetInitialState() *GameState {
 		initialState.InitialEntities[i] = entity
 	}
 	
+	// Carry the triggers over, handing reactions to the copied entities
+	initialState.Triggers = copyTriggers(gs.Triggers, initialState.Initiative)
+	
 	return initialState
 }
 
+// initialDice returns a source that replays the rolls made after initiative.
+// Sources that can't be copied, such as manual entry, carry on as they are.
+func (gs *GameState) initialDice() dice.Source {
+	if cloner, ok := gs.InitialDice.(interface{ Clone() dice.Source }); ok {
+		return cloner.Clone()
+	}
+	return gs.Dice
+}
+
+// snapshot deep copies the entity, so that nothing done to it mid-combat
+// changes the copy. Action cards and the controller are shared.
+func (e *Entity) snapshot() *Entity {
+	c := *e
+	c.Attributes = e.Attributes.copy()
+	c.Saves = copyMap(e.Saves)
+	c.Skills = copyMap(e.Skills)
+	c.Proficiencies = copyMap(e.Proficiencies)
+	c.ItemBonuses = copyMap(e.ItemBonuses)
+	c.Resistances = copyMap(e.Resistances)
+	c.Weaknesses = copyMap(e.Weaknesses)
+	c.Immunities = copyMap(e.Immunities)
+	c.Ammunition = copyAmmunition(e.Ammunition)
+	c.Conditions = copyConditions(e.Conditions)
+	c.FortuneEffects = append([]FortuneEffect(nil), e.FortuneEffects...)
+	c.Weapons = append([]WieldedWeapon(nil), e.Weapons...)
+	c.ActionCards = append([]*ActionCard(nil), e.ActionCards...)
+	c.Shield = e.Shield.copy()
+	c.Spellcasting = e.Spellcasting.copy()
+	c.MapCounter = 0
+	c.attacksThisTurn = nil
+	return &c
+}
+
+// copyMap copies one of an entity's statistic tables
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
 type EndTurnStep struct {
 	BaseStep
 	Entity *Entity
@@ -199,9 +266,10 @@ func (e *StartTurnStep) Metadata() map[string]interface{} {
 func (gs *GameState) RollInitiative() {
 	// Roll initiative for each entity
 	for _, entity := range gs.Initiative {
-		roll := dice.Roll(20)
-		fmt.Printf("%s rolls initiative: %d\n", entity.Name, roll)
-		entity.Initiative = roll
+		// Initiative is usually a Perception check
+		record := rollD20(gs, entity, entity.Breakdown(StatPerception).DiceModifiers())
+		gs.Printf("%s rolls initiative: %s\n", entity.Name, record.String())
+		entity.Initiative = record.Total
 	}
 
 	// Sort by initiative score in descending order
@@ -209,7 +277,14 @@ func (gs *GameState) RollInitiative() {
 		return gs.Initiative[i].Initiative > gs.Initiative[j].Initiative
 	})
 
-	fmt.Println("Initiative order determined")
+	gs.Printf("Initiative order determined\n")
+}
+
+// Printf writes human-readable progress to the game's console output
+func (gs *GameState) Printf(format string, args ...interface{}) {
+	if gs.Out != nil {
+		fmt.Fprintf(gs.Out, format, args...)
+	}
 }
 
 func (gs *GameState) LogEvent(message string, metadata map[string]interface{}) {
@@ -236,6 +311,12 @@ func (gs *GameState) NextTurn() *Entity {
 	
 	// End its turn if it's a valid entity
 	if entity != nil {
+		gs.endTurnConditions(entity)
+
+		// The multiple attack penalty only applies during the entity's own turn
+		entity.MapCounter = 0
+		entity.attacksThisTurn = nil
+
 		// Create end turn step
 		endTurnStep := &EndTurnStep{
 			Entity: entity,
@@ -256,45 +337,32 @@ func (gs *GameState) NextTurn() *Entity {
 		})
 	}
 	
-	// Advance to the next entity in initiative order
-	gs.CurrentTurn = (gs.CurrentTurn + 1) % len(gs.Initiative)
-	
-	// If all entities are dead except one, end combat
-	aliveCount := 0
-	var lastAlive *Entity
-	for _, e := range gs.Initiative {
-		if e.IsAlive() {
-			aliveCount++
-			lastAlive = e
-		}
-	}
-	
-	// If only one entity is alive, end combat
-	if aliveCount <= 1 && lastAlive != nil {
-		gs.LogEvent(fmt.Sprintf("%s wins the combat!", lastAlive.Name), map[string]interface{}{
-			"winner":      lastAlive.Name,
-			"winner_id":   lastAlive.Id.String(),
-			"winner_hp":   lastAlive.HP,
-			"combat_over": true,
-		})
-		
-		// Output the winner
-		fmt.Printf("%s wins the combat!\n", lastAlive.Name)
-		
-		// Return nil to indicate combat is over
+	// If only one faction is left standing, end combat
+	if gs.checkCombatOver() {
 		return nil
 	}
 	
+	// Advance to the next entity in initiative order
+	gs.advanceTurn()
+	
 	// Reset actions for new entity's turn
 	entity = gs.GetCurrentTurnEntity()
 	entity.ResetTurnResources()
 	
 	// Skip dead entities
 	for !entity.IsAlive() {
-		gs.CurrentTurn = (gs.CurrentTurn + 1) % len(gs.Initiative)
+		gs.advanceTurn()
 		entity = gs.GetCurrentTurnEntity()
 		entity.ResetTurnResources()
 	}
+	gs.startTurnConditions(entity)
+	
+	// Dying entities try to recover, and nobody unconscious can act
+	gs.recoveryCheck(entity)
+	if !entity.IsConscious() {
+		entity.ActionsRemaining = 0
+		entity.ReactionsRemaining = 0
+	}
 	
 	// Create start turn step
 	startTurnStep := &StartTurnStep{
@@ -315,11 +383,58 @@ func (gs *GameState) NextTurn() *Entity {
 		"entity_name": entity.Name,
 	})
 	
-	fmt.Printf("%s's turn begins\n", entity.Name)
+	gs.Printf("%s's turn begins\n", entity.Name)
 	
 	return entity
 }
 
+// advanceTurn moves to the next slot in initiative order, starting a new round when it wraps
+func (gs *GameState) advanceTurn() {
+	gs.CurrentTurn = (gs.CurrentTurn + 1) % len(gs.Initiative)
+	if gs.CurrentTurn == 0 {
+		gs.Round++
+	}
+}
+
+// checkCombatOver ends the combat once the conscious entities all belong to one
+// faction (or none are left) and reports whether it is over. Dying and
+// unconscious entities can't fight on, so they don't keep a faction in the combat.
+func (gs *GameState) checkCombatOver() bool {
+	if gs.Over {
+		return true
+	}
+	factions := map[Faction]bool{}
+	var lastFaction Faction
+	for _, e := range gs.Initiative {
+		if e.IsConscious() {
+			factions[e.Faction] = true
+			lastFaction = e.Faction
+		}
+	}
+	if len(factions) > 1 {
+		return false
+	}
+
+	gs.Over = true
+	if len(factions) == 0 {
+		gs.LogEvent("Nobody is left standing; the combat is a draw.", map[string]interface{}{
+			"combat_over": true,
+			"round":       gs.Round,
+		})
+		gs.Printf("Nobody is left standing; the combat is a draw.\n")
+		return true
+	}
+
+	gs.Winner = &lastFaction
+	gs.LogEvent(fmt.Sprintf("%s win the combat!", lastFaction), map[string]interface{}{
+		"winner":      lastFaction.String(),
+		"combat_over": true,
+		"round":       gs.Round,
+	})
+	gs.Printf("%s win the combat!\n", lastFaction)
+	return true
+}
+
 // GetCurrentTurnEntity returns a pointer to the entity whose turn it currently is
 func (gs *GameState) GetCurrentTurnEntity() *Entity {
 	if len(gs.Initiative) == 0 {
@@ -0,0 +1,182 @@
+package game
+
+import (
+	"io"
+	dice "pf2eEngine/util"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// command is a card played by an entity, as a client would send it
+type command struct {
+	actor  string
+	card   string // Prefix of the card's name, e.g. "Strike"
+	target string
+}
+
+// duelSpawns is a fighter and a goblin standing next to each other
+func duelSpawns() []Spawn {
+	fighter := NewCharacter("Fighter", 1, 20, NewAttributes(4, 2, 2, 0, 1, 0), GoodGuys)
+	fighter.SetProficiency(StatAttack, Trained)
+	fighter.Wield(Longsword, 1)
+	goblin := NewEntity("Goblin", 18, 16, BadGuys)
+	goblin.Wield(Dogslicer, 1)
+	return []Spawn{NewSpawn(fighter, 0, 0), NewSpawn(goblin, 1, 0)}
+}
+
+func newTestGame(spawns []Spawn, src dice.Source) *GameState {
+	gs := newGameState(spawns, 10, 10, src, io.Discard)
+	for _, e := range gs.Initiative {
+		e.Controller = nil
+	}
+	return gs
+}
+
+// scriptDice has the game roll the given results from now on, panicking with
+// a *dice.ScriptError if it rolls anything else
+func scriptDice(gs *GameState, rolls ...dice.ScriptedRoll) *dice.ScriptedSource {
+	src := dice.NewScriptedSource(nil, rolls...)
+	gs.Dice = src
+	return src
+}
+
+func entityNamed(gs *GameState, name string) *Entity {
+	for _, e := range gs.Initiative {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// play executes the card the entity holds whose name starts with prefix
+func play(t *testing.T, gs *GameState, actor, prefix string, params map[string]interface{}) {
+	t.Helper()
+	e := entityNamed(gs, actor)
+	for _, card := range e.ActionCards {
+		if !strings.HasPrefix(card.Name, prefix) {
+			continue
+		}
+		action, err := card.GenerateAction(gs, e, params)
+		if err != nil {
+			t.Fatalf("%s cannot play %s: %v", actor, card.Name, err)
+		}
+		ExecuteAction(gs, e, action)
+		return
+	}
+	t.Fatalf("%s has no %s card", actor, prefix)
+}
+
+func runCommands(t *testing.T, gs *GameState, commands []command) []string {
+	t.Helper()
+	start := len(gs.Logs)
+	for _, c := range commands {
+		e := entityNamed(gs, c.actor)
+		e.ActionsRemaining = 3
+		play(t, gs, c.actor, c.card, map[string]interface{}{TargetID: entityNamed(gs, c.target).Id.String()})
+	}
+	var messages []string
+	for _, entry := range gs.Logs[start:] {
+		messages = append(messages, entry.Message)
+	}
+	return messages
+}
+
+var duelCommands = []command{
+	{"Fighter", "Strike", "Goblin"},
+	{"Goblin", "Strike", "Fighter"},
+	{"Fighter", "Strike", "Goblin"},
+	{"Goblin", "Strike", "Fighter"},
+	{"Goblin", "Strike", "Fighter"},
+}
+
+func TestSeedAndCommandsReplayCombat(t *testing.T) {
+	first := newTestGame(duelSpawns(), dice.NewSeededSource(7))
+	second := newTestGame(duelSpawns(), dice.NewSeededSource(7))
+	firstLog := runCommands(t, first, duelCommands)
+	secondLog := runCommands(t, second, duelCommands)
+	if len(firstLog) == 0 {
+		t.Fatal("the commands logged nothing")
+	}
+	if !reflect.DeepEqual(firstLog, secondLog) {
+		t.Errorf("same seed and commands gave different combats:\n%v\n%v", firstLog, secondLog)
+	}
+}
+
+func TestInitialStateReplaysCombat(t *testing.T) {
+	gs := newTestGame(duelSpawns(), dice.NewSeededSource(11))
+	played := runCommands(t, gs, duelCommands)
+	replay := gs.GetInitialState()
+	replayed := runCommands(t, replay, duelCommands)
+	if !reflect.DeepEqual(played, replayed) {
+		t.Errorf("the initial state replayed a different combat:\n%v\n%v", played, replayed)
+	}
+	for _, name := range []string{"Fighter", "Goblin"} {
+		if got, want := entityNamed(replay, name).HP, entityNamed(gs, name).HP; got != want {
+			t.Errorf("%s ended the replay on %d HP, want %d", name, got, want)
+		}
+	}
+}
+
+func TestInitialStateIsDeepCopy(t *testing.T) {
+	spawns := duelSpawns()
+	fighter := spawns[0].Unit
+	controller := NewAIController()
+	fighter.Controller = controller
+	fighter.TempHP = 4
+	fighter.AddCondition(Condition{Name: Frightened, Value: 1})
+	fighter.AddFortuneEffect(FortuneEffect{Name: "Lucky", Fortune: dice.FortuneRoll, Rolls: CheckRollKind})
+	fighter.SetResistance(Fire, 5)
+	fighter.SetSaves(5, 7, 4)
+	fighter.SetSkill(Athletics, 7)
+	fighter.AddImmunity(Mental)
+	fighter.SetWeakness(Cold, 1)
+	gs := newGameState(spawns, 5, 5, dice.NewSeededSource(3), io.Discard)
+
+	// Change everything a combat might change on the live entity
+	fighter.SetResistance(Fire, 10)
+	fighter.AddImmunity(Poison)
+	fighter.SetWeakness(Cold, 2)
+	fighter.Saves[Fortitude] = 99
+	fighter.Skills[Athletics] = 99
+	fighter.SetProficiency(StatAttack, Legendary)
+	fighter.SetItemBonus(StatAC, 3)
+	fighter.Conditions[0].Value = 3
+	fighter.FortuneEffects[0].Uses = 9
+	fighter.TempHP = 0
+
+	initial := entityNamed(gs.GetInitialState(), "Fighter")
+	if initial == fighter {
+		t.Fatal("the initial state shares the live entity")
+	}
+	if initial.Resistances[Fire] != 5 || initial.Immunities[Poison] || initial.Weaknesses[Cold] != 1 {
+		t.Errorf("damage tables leaked into the initial state: %v %v %v", initial.Resistances, initial.Immunities, initial.Weaknesses)
+	}
+	if initial.Saves[Fortitude] == 99 || initial.Skills[Athletics] == 99 {
+		t.Errorf("saves or skills leaked into the initial state: %v %v", initial.Saves, initial.Skills)
+	}
+	if initial.Proficiencies[StatAttack] != Trained || initial.ItemBonuses[StatAC] != 0 {
+		t.Errorf("proficiencies or item bonuses leaked into the initial state: %v %v", initial.Proficiencies, initial.ItemBonuses)
+	}
+	if initial.ConditionValue(Frightened) != 1 {
+		t.Errorf("initial state has frightened %d, want 1", initial.ConditionValue(Frightened))
+	}
+	if len(initial.FortuneEffects) != 1 || initial.FortuneEffects[0].Uses != 0 {
+		t.Errorf("initial state has fortune effects %v, want the one from the start", initial.FortuneEffects)
+	}
+	if initial.TempHP != 4 {
+		t.Errorf("initial state has %d temporary HP, want 4", initial.TempHP)
+	}
+	if initial.Controller != controller {
+		t.Errorf("initial state lost the entity's controller")
+	}
+}
+
+func TestInitialStateKeepsUncopyableDice(t *testing.T) {
+	src := dice.NewScriptedSource(dice.NewSeededSource(1))
+	gs := newTestGame(duelSpawns(), src)
+	if got := gs.GetInitialState().Dice; got != dice.Source(src) {
+		t.Errorf("initial state rolls with %T, want the game's own source", got)
+	}
+}
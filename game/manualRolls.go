@@ -0,0 +1,79 @@
+package game
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	dice "pf2eEngine/util"
+)
+
+// RollRequest asks a player for the result of a die they rolled at the table
+type RollRequest struct {
+	ID     uuid.UUID
+	Entity *Entity
+	Sides  int
+	Error  string // Why the previous answer was rejected, if it was
+}
+
+// RollPrompter asks the client controlling an entity for a physical die result
+type RollPrompter interface {
+	RequestRoll(req RollRequest) (int, error)
+}
+
+// ManualRollSource lets players roll their own dice: whenever a player-controlled
+// entity rolls, the engine pauses and asks that player's client for the result.
+// Everyone else's rolls, and any roll the client fails to answer, use the fallback.
+type ManualRollSource struct {
+	gs       *GameState
+	prompter RollPrompter
+	fallback dice.Source
+}
+
+// NewManualRollSource creates a manual roll source for gs that falls back to
+// the game's current roll source
+func NewManualRollSource(gs *GameState, prompter RollPrompter) *ManualRollSource {
+	return &ManualRollSource{gs: gs, prompter: prompter, fallback: gs.Dice}
+}
+
+// Roll asks the rolling player for a result until they give one that fits the die
+func (m *ManualRollSource) Roll(sides int) int {
+	entity := m.gs.rollingEntity()
+	if entity == nil || !isPlayerControlled(entity) {
+		return m.fallback.Roll(sides)
+	}
+
+	req := RollRequest{ID: uuid.New(), Entity: entity, Sides: sides}
+	for {
+		value, err := m.prompter.RequestRoll(req)
+		if err != nil {
+			m.gs.Printf("No roll received for %s (%v); rolling for them.\n", entity.Name, err)
+			return m.fallback.Roll(sides)
+		}
+		if value >= 1 && value <= sides {
+			return value
+		}
+		req.Error = fmt.Sprintf("%d is not a possible result on a d%d", value, sides)
+	}
+}
+
+func isPlayerControlled(e *Entity) bool {
+	_, ok := e.Controller.(*PlayerController)
+	return ok
+}
+
+// rollAs marks e as the entity making the following rolls and returns a
+// function that restores the previous roller
+func (gs *GameState) rollAs(e *Entity) func() {
+	previous := gs.roller
+	gs.roller = e
+	return func() {
+		gs.roller = previous
+	}
+}
+
+// rollingEntity is the entity currently rolling, defaulting to whoever's turn it is
+func (gs *GameState) rollingEntity() *Entity {
+	if gs.roller != nil {
+		return gs.roller
+	}
+	return gs.GetCurrentTurnEntity()
+}
@@ -0,0 +1,310 @@
+package game
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// MoveStep is taken as a moving entity is about to leave one square for the
+// next. Reactions to it, such as Reactive Strike, interrupt the move while the
+// entity is still in the square it is leaving.
+type MoveStep struct {
+	BaseStep
+	Entity      *Entity
+	From        Position
+	To          Position
+	Action      string // The move action the entity is using, e.g. "Stride"
+	NoReactions bool   // Set for Steps and teleports, which don't trigger reactions to movement
+}
+
+func NewMoveStep(entity *Entity, from, to Position, action string) MoveStep {
+	return MoveStep{
+		BaseStep: BaseStep{
+			StepType: EntityMove,
+			metadata: map[string]interface{}{
+				"entity_id":   entity.Id.String(),
+				"entity_name": entity.Name,
+				"from":        [2]int{from.X, from.Y},
+				"to":          [2]int{to.X, to.Y},
+				"action":      action,
+			},
+		},
+		Entity: entity,
+		From:   from,
+		To:     to,
+		Action: action,
+	}
+}
+
+// Path lists the squares an entity passes through on the shortest way from
+// one position to another, one square at a time and not including the start.
+// Length is measured in feet with the PF2E diagonal rule, as pathLength does.
+// The path goes around occupied squares and walls; it is empty if there is no way through.
+func (g *Grid) Path(from, to Position) []Position {
+	start := pathState{pos: from}
+	cost := map[pathState]int{start: 0}
+	previous := map[pathState]pathState{}
+	open := &pathQueue{{state: start}}
+	seq := 0
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathNode)
+		if current.cost > cost[current.state] {
+			continue // Already reached more cheaply
+		}
+		if current.state.pos == to {
+			if from == to {
+				return nil
+			}
+			var path []Position
+			for s := current.state; s != start; s = previous[s] {
+				path = append([]Position{s.pos}, path...)
+			}
+			return path
+		}
+		for _, d := range directions {
+			next := pathState{pos: Position{X: current.state.pos.X + d.X, Y: current.state.pos.Y + d.Y}, odd: current.state.odd}
+			if !g.IsValidPosition(next.pos) || g.IsOccupied(next.pos) || g.IsWall(next.pos) {
+				continue
+			}
+			step := 5
+			if d.X != 0 && d.Y != 0 {
+				if next.odd {
+					step = 10
+				}
+				next.odd = !next.odd
+			}
+			if c, seen := cost[next]; seen && c <= current.cost+step {
+				continue
+			}
+			cost[next] = current.cost + step
+			previous[next] = current.state
+			seq++
+			heap.Push(open, pathNode{state: next, cost: current.cost + step, seq: seq})
+		}
+	}
+	return nil
+}
+
+// pathState is a square reached while searching for a path, along with
+// whether an odd number of diagonals led there, making the next one cost 10 feet
+type pathState struct {
+	pos Position
+	odd bool
+}
+
+type pathNode struct {
+	state pathState
+	cost  int // Feet moved to get here
+	seq   int // Order found in, so that ties are broken the same way every time
+}
+
+// pathQueue is a priority queue of the cheapest squares found so far
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	return q[i].seq < q[j].seq
+}
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathNode)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// pathLength is how far in feet the path from a square is, with every other
+// diagonal costing 10 feet
+func pathLength(from Position, path []Position) int {
+	feet, diagonals := 0, 0
+	for _, next := range path {
+		if next.X != from.X && next.Y != from.Y {
+			diagonals++
+			if diagonals%2 == 0 {
+				feet += 10
+				from = next
+				continue
+			}
+		}
+		feet += 5
+		from = next
+	}
+	return feet
+}
+
+// pathWithin cuts the path short at the last square within the given feet
+func pathWithin(from Position, path []Position, feet int) []Position {
+	for i := len(path); i > 0; i-- {
+		if pathLength(from, path[:i]) <= feet {
+			return path[:i]
+		}
+	}
+	return nil
+}
+
+// directions are the offsets to the eight squares around a square, diagonals first
+var directions = []Position{
+	{X: 1, Y: 1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: -1, Y: -1},
+	{X: 1, Y: 0}, {X: -1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: -1},
+}
+
+// MoveAlong moves the entity along the path one square at a time as part of
+// the named move action, taking a move step before each square it leaves.
+// The move stops short if a reaction leaves the entity unable to carry on or
+// its way becomes blocked. It returns where the entity ends up.
+func MoveAlong(gs *GameState, entity *Entity, path []Position, action string) Position {
+	return moveAlong(gs, entity, path, action, false)
+}
+
+// moveAlong is MoveAlong, optionally moving without triggering reactions
+func moveAlong(gs *GameState, entity *Entity, path []Position, action string, noReactions bool) Position {
+	current := gs.Grid.GetEntityPosition(entity)
+	for _, next := range path {
+		step := NewMoveStep(entity, current, next, action)
+		step.NoReactions = noReactions
+		executeStep(gs, step,
+			fmt.Sprintf("%s moves from (%d,%d) to (%d,%d).", entity.Name, current.X, current.Y, next.X, next.Y))
+		if !entity.IsConscious() || entity.HasCondition(Immobilized) || entity.HasCondition(Prone) {
+			gs.Printf("%s's movement is stopped at (%d,%d).\n", entity.Name, current.X, current.Y)
+			break
+		}
+		if !gs.Grid.MoveEntity(current, next) {
+			gs.Printf("%s's way is blocked at (%d,%d).\n", entity.Name, current.X, current.Y)
+			break
+		}
+		current = next
+	}
+	return current
+}
+
+// Teleport moves the entity straight to a square without passing through the
+// ones between, taking a single move step that doesn't trigger reactions to
+// movement. It reports whether the entity arrived.
+func Teleport(gs *GameState, entity *Entity, to Position, action string) bool {
+	from := gs.Grid.GetEntityPosition(entity)
+	step := NewMoveStep(entity, from, to, action)
+	step.NoReactions = true
+	executeStep(gs, step, fmt.Sprintf("%s teleports from (%d,%d) to (%d,%d).", entity.Name, from.X, from.Y, to.X, to.Y))
+	if !gs.Grid.MoveEntity(from, to) {
+		gs.Printf("%s fails to teleport: (%d,%d) is blocked.\n", entity.Name, to.X, to.Y)
+		return false
+	}
+	return true
+}
+
+// canMove checks the actor can use a move action
+func canMove(actor *Entity) error {
+	if actor.HasCondition(Immobilized) {
+		return errors.New("actor is immobilized")
+	}
+	if actor.HasCondition(Prone) {
+		return errors.New("actor is prone and must stand first")
+	}
+	return nil
+}
+
+// strideToSquare creates a Stride to the square chosen with a "position"
+// param, going around anything in the way as long as the way there is
+// within the actor's Speed
+func strideToSquare(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+	to, err := getSquare(gs, actor, PositionParam, []SquareCriterion{InBounds(), Unoccupied()}, params)
+	if err != nil {
+		return Action{}, err
+	}
+	from := gs.Grid.GetEntityPosition(actor)
+	path := gs.Grid.Path(from, to)
+	if len(path) == 0 {
+		return Action{}, fmt.Errorf("there is no way to (%d,%d)", to.X, to.Y)
+	}
+	if feet := pathLength(from, path); feet > actor.LandSpeed() {
+		return Action{}, fmt.Errorf("(%d,%d) is %d feet away (Speed: %d)", to.X, to.Y, feet, actor.LandSpeed())
+	}
+	return Action{
+		Name: "Stride",
+		Cost: 1,
+		perform: func(gs *GameState, actor *Entity) {
+			from := gs.Grid.GetEntityPosition(actor)
+			end := MoveAlong(gs, actor, path, "Stride")
+			gs.Printf("%s strides from (%d,%d) to (%d,%d).\n", actor.Name, from.X, from.Y, end.X, end.Y)
+		},
+	}, nil
+}
+
+// NewStepCard creates a Step into an adjacent square chosen with a "position"
+// param. Unlike other movement, Stepping doesn't trigger reactions.
+func NewStepCard() *ActionCard {
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        "Step",
+		Type:        OneActionCard,
+		Description: "Carefully move 5 feet without triggering reactions.",
+		Traits:      []ActionTrait{MoveTrait},
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			if err := canMove(actor); err != nil {
+				return Action{}, err
+			}
+			to, err := getSquare(gs, actor, PositionParam, []SquareCriterion{InBounds(), Unoccupied(), SquareRange(5)}, params)
+			if err != nil {
+				return Action{}, err
+			}
+			return Action{
+				Name: "Step",
+				Cost: 1,
+				perform: func(gs *GameState, actor *Entity) {
+					moveAlong(gs, actor, []Position{to}, "Step", true)
+				},
+			}, nil
+		},
+	}
+}
+
+// leapDistance is how far the entity can Leap horizontally: 10 feet with a
+// Speed of at least 15 feet, or 15 feet with a Speed of at least 30 feet
+func (e *Entity) leapDistance() int {
+	switch speed := e.LandSpeed(); {
+	case speed >= 30:
+		return 15
+	case speed >= 15:
+		return 10
+	}
+	return 0
+}
+
+// NewLeapCard creates a short jump to a square chosen with a "position"
+// param, clearing whatever is between. Leaving its square triggers
+// reactions as other movement does.
+func NewLeapCard() *ActionCard {
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        "Leap",
+		Type:        OneActionCard,
+		Description: "Jump 10 feet horizontally, or 15 feet if your Speed is at least 30 feet.",
+		Traits:      []ActionTrait{MoveTrait},
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			if err := canMove(actor); err != nil {
+				return Action{}, err
+			}
+			distance := actor.leapDistance()
+			if distance == 0 {
+				return Action{}, errors.New("actor's Speed is too low to Leap")
+			}
+			to, err := getSquare(gs, actor, PositionParam, []SquareCriterion{InBounds(), Unoccupied(), SquareRange(distance), LineOfEffect()}, params)
+			if err != nil {
+				return Action{}, err
+			}
+			return Action{
+				Name: "Leap",
+				Cost: 1,
+				perform: func(gs *GameState, actor *Entity) {
+					MoveAlong(gs, actor, []Position{to}, "Leap")
+				},
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"math"
+	dice "pf2eEngine/util"
+)
+
+// AttackOdds is the exact outcome distribution of a single attack
+type AttackOdds struct {
+	MAP            int                         // Number of earlier attacks this turn, capped at 2
+	Bonus          int                         // Attack bonus after the multiple attack penalty
+	Degrees        map[DegreeOfSuccess]float64 // Probability of each degree of success
+	ExpectedDamage float64                     // Mean damage, counting critical hits as double
+}
+
+// HitChance is the probability that the attack deals damage
+func (o AttackOdds) HitChance() float64 {
+	return o.Degrees[Success] + o.Degrees[CriticalSuccess]
+}
+
+// CheckOdds returns the probability of each degree of success for a check
+// with the given bonus against dc, including the natural 1 and 20 adjustments
+func CheckOdds(bonus, dc int, fortune dice.Fortune) map[DegreeOfSuccess]float64 {
+	odds := map[DegreeOfSuccess]float64{
+		CriticalFailure: 0,
+		Failure:         0,
+		Success:         0,
+		CriticalSuccess: 0,
+	}
+	for roll := 1; roll <= 20; roll++ {
+		odds[calculateDegreeOfSuccess(roll, roll+bonus, dc)] += d20Chance(roll, fortune)
+	}
+	return odds
+}
+
+// d20Chance is the probability of the kept d20 showing roll
+func d20Chance(roll int, fortune dice.Fortune) float64 {
+	switch fortune {
+	case dice.FortuneRoll:
+		return float64(2*roll-1) / 400
+	case dice.MisfortuneRoll:
+		return float64(41-2*roll) / 400
+	default:
+		return 1.0 / 20
+	}
+}
+
+// ExpectedDamage returns the mean total of the damage rolls, leaving out
+// persistent damage, which depends on how long it lasts
+func ExpectedDamage(rolls []DamageRoll) float64 {
+	total := 0.0
+	for _, dr := range rolls {
+		if dr.Persistent {
+			continue
+		}
+		total += float64(dr.Bonus) + expectedDice(dr.Count, dr.Die, dr.Keep, dr.KeepLowest)
+	}
+	return total
+}
+
+// expectedDice is the mean of the kept dice, computed from the order statistics
+func expectedDice(count, sides, keep int, keepLowest bool) float64 {
+	if count <= 0 {
+		return 0
+	}
+	if keep <= 0 || keep >= count {
+		return float64(count) * float64(sides+1) / 2
+	}
+	// The j-th smallest die is the (count-j+1)-th largest, so keeping the lowest
+	// dice keeps the largest ranks
+	first, last := 1, keep
+	if keepLowest {
+		first, last = count-keep+1, count
+	}
+	total := 0.0
+	for v := 1; v <= sides; v++ {
+		// E[X] = sum over v of P(X >= v); the rank-th largest die is at least v
+		// when at least rank dice are
+		tail := atLeast(count, float64(sides-v+1)/float64(sides))
+		for rank := first; rank <= last; rank++ {
+			total += tail[rank]
+		}
+	}
+	return total
+}
+
+// atLeast lists, for each k from 0 to n, the probability that at least k of n
+// independent events of probability p happen
+func atLeast(n int, p float64) []float64 {
+	tail := make([]float64, n+2)
+	switch {
+	case p >= 1:
+		for k := 0; k <= n; k++ {
+			tail[k] = 1
+		}
+		return tail
+	case p <= 0:
+		tail[0] = 1
+		return tail
+	}
+	for k := n; k >= 0; k-- {
+		tail[k] = math.Min(tail[k+1]+binomialChance(n, k, p), 1)
+	}
+	return tail
+}
+
+// binomialChance is the probability that exactly k of n independent events of
+// probability p happen, where 0 < p < 1. It is worked out with logarithms
+// because the binomial coefficient overflows an int for a few dozen dice.
+func binomialChance(n, k int, p float64) float64 {
+	return math.Exp(logFactorial(n) - logFactorial(k) - logFactorial(n-k) +
+		float64(k)*math.Log(p) + float64(n-k)*math.Log1p(-p))
+}
+
+// logFactorial is the natural logarithm of n!
+func logFactorial(n int) float64 {
+	lg, _ := math.Lgamma(float64(n + 1))
+	return lg
+}
+
+// multipleAttackPenalty is the penalty for an attack after attacks earlier in the turn
+func multipleAttackPenalty(attacksMade int) int {
+	return -5 * min(attacksMade, 2)
+}
+
+// CalculateAttackOdds works out the exact odds of an attack against ac after
+// attacksMade earlier attacks this turn, without rolling anything
+func CalculateAttackOdds(attack BaseAttack, ac int, attacksMade int, fortune dice.Fortune) AttackOdds {
+	attacksMade = min(attacksMade, 2)
+	bonus := attack.Bonus + multipleAttackPenalty(attacksMade)
+	degrees := CheckOdds(bonus, ac, fortune)
+	damage := ExpectedDamage(attack.Damage)
+	return AttackOdds{
+		MAP:            attacksMade,
+		Bonus:          bonus,
+		Degrees:        degrees,
+		ExpectedDamage: degrees[Success]*damage + degrees[CriticalSuccess]*2*damage,
+	}
+}
+
+// CalculateAttackProfile returns the odds of the first, second and third attack
+// of a turn against ac
+func CalculateAttackProfile(attack BaseAttack, ac int, fortune dice.Fortune) []AttackOdds {
+	profile := make([]AttackOdds, 0, 3)
+	for attacksMade := 0; attacksMade <= 2; attacksMade++ {
+		profile = append(profile, CalculateAttackOdds(attack, ac, attacksMade, fortune))
+	}
+	return profile
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// bruteForceDice averages the kept dice over every possible roll
+func bruteForceDice(count, sides, keep int, keepLowest bool) float64 {
+	if keep <= 0 || keep > count {
+		keep = count
+	}
+	rolls := make([]int, count)
+	total, outcomes := 0, 0
+	var roll func(i int)
+	roll = func(i int) {
+		if i == count {
+			sorted := append([]int(nil), rolls...)
+			sort.Ints(sorted)
+			kept := sorted[count-keep:]
+			if keepLowest {
+				kept = sorted[:keep]
+			}
+			for _, v := range kept {
+				total += v
+			}
+			outcomes++
+			return
+		}
+		for v := 1; v <= sides; v++ {
+			rolls[i] = v
+			roll(i + 1)
+		}
+	}
+	roll(0)
+	return float64(total) / float64(outcomes)
+}
+
+func TestExpectedDiceMatchesEnumeration(t *testing.T) {
+	for count := 1; count <= 4; count++ {
+		for sides := 2; sides <= 6; sides++ {
+			for keep := 0; keep <= count; keep++ {
+				for _, keepLowest := range []bool{false, true} {
+					got := expectedDice(count, sides, keep, keepLowest)
+					want := bruteForceDice(count, sides, keep, keepLowest)
+					if math.Abs(got-want) > 1e-9 {
+						t.Errorf("expectedDice(%d, %d, %d, %v) = %v, want %v", count, sides, keep, keepLowest, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestExpectedDamageMatchesEnumeration(t *testing.T) {
+	rolls := []DamageRoll{
+		{Die: 6, Count: 4, Keep: 3, Bonus: 2, Type: Slashing},
+		{Die: 4, Count: 2, Keep: 1, KeepLowest: true, Type: Fire},
+		{Die: 8, Count: 1, Bonus: -1, Type: Piercing},
+		{Die: 6, Count: 3, Type: Bleed, Persistent: true},
+	}
+	want := bruteForceDice(4, 6, 3, false) + 2 + bruteForceDice(2, 4, 1, true) + bruteForceDice(1, 8, 0, false) - 1
+	if got := ExpectedDamage(rolls); math.Abs(got-want) > 1e-9 {
+		t.Errorf("ExpectedDamage = %v, want %v", got, want)
+	}
+}
+
+// extremeDie is the exact mean of the highest, or lowest, of count dice
+func extremeDie(count, sides int, lowest bool) float64 {
+	total := 0.0
+	for v := 1; v <= sides; v++ {
+		if lowest {
+			total += math.Pow(float64(sides-v+1)/float64(sides), float64(count))
+		} else {
+			total += 1 - math.Pow(float64(v-1)/float64(sides), float64(count))
+		}
+	}
+	return total
+}
+
+func TestExpectedDiceManyDice(t *testing.T) {
+	tests := []struct {
+		count, sides, keep int
+		keepLowest         bool
+		want               float64
+	}{
+		{70, 6, 1, false, extremeDie(70, 6, false)},
+		{70, 6, 1, true, extremeDie(70, 6, true)},
+		{100, 1000, 1, false, extremeDie(100, 1000, false)},
+		{70, 6, 70, false, 245},
+		{100, 1000, 0, false, 50050},
+	}
+	for _, tt := range tests {
+		got := expectedDice(tt.count, tt.sides, tt.keep, tt.keepLowest)
+		if math.IsNaN(got) || math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("expectedDice(%d, %d, %d, %v) = %v, want %v", tt.count, tt.sides, tt.keep, tt.keepLowest, got, tt.want)
+		}
+	}
+}
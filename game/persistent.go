@@ -0,0 +1,140 @@
+package game
+
+import (
+	"fmt"
+)
+
+// PersistentDamage is the condition of taking damage at the end of each turn
+// until a flat check ends it. Its Damage says how much and of what type.
+const PersistentDamage ConditionName = "PERSISTENT_DAMAGE"
+
+const (
+	persistentRecoveryDC = 15
+	assistedRecoveryDC   = 10 // With particularly appropriate help, e.g. dousing flames
+)
+
+// PersistentDamageStep is taken each time persistent damage is dealt
+type PersistentDamageStep struct {
+	BaseStep
+	Entity    *Entity
+	Condition Condition
+}
+
+func NewPersistentDamageStep(e *Entity, c Condition) PersistentDamageStep {
+	return PersistentDamageStep{
+		BaseStep: BaseStep{
+			StepType: PersistentDamageTick,
+			metadata: map[string]interface{}{
+				"Entity": e.Name,
+				"Damage": c.Damage.String(),
+				"Source": c.Source,
+			},
+		},
+		Entity:    e,
+		Condition: c,
+	}
+}
+
+// average is the mean result of the roll
+func (dr DamageRoll) average() float64 {
+	return float64(dr.Bonus) + expectedDice(dr.Count, dr.Die, dr.Keep, dr.KeepLowest)
+}
+
+// AddPersistentDamage gives the entity persistent damage, unless it is immune to the type
+func (e *Entity) AddPersistentDamage(roll DamageRoll, source string) bool {
+	for _, key := range appliesTo(roll.Type) {
+		if e.Immunities[key] {
+			return false
+		}
+	}
+	roll.Persistent = true
+	e.AddCondition(Condition{Name: PersistentDamage, Damage: &roll, Source: source})
+	return true
+}
+
+// RemovePersistentDamage ends the entity's persistent damage of one type
+func (e *Entity) RemovePersistentDamage(t DamageType) {
+	kept := e.Conditions[:0]
+	for _, c := range e.Conditions {
+		if c.Name != PersistentDamage || c.Damage.Type != t {
+			kept = append(kept, c)
+		}
+	}
+	e.Conditions = kept
+}
+
+// applyPersistentDamage gives the target the persistent damage of a hit.
+// Persistent damage from a critical hit is doubled along with the rest.
+func (gs *GameState) applyPersistentDamage(damage Damage) {
+	for _, roll := range damage.Persistent {
+		if damage.Critical {
+			roll.Count *= 2
+			roll.Bonus *= 2
+		}
+		if damage.Target.AddPersistentDamage(roll, entityName(damage.Source)) {
+			gs.Printf("%s is now taking %s damage.\n", damage.Target.Name, roll.String())
+		}
+	}
+}
+
+// persistentDamage deals each of the entity's persistent damage at the end of
+// its turn, then has it attempt a flat check to end it
+func (gs *GameState) persistentDamage(e *Entity) {
+	var persistent []Condition
+	for _, c := range e.Conditions {
+		if c.Name == PersistentDamage {
+			persistent = append(persistent, c)
+		}
+	}
+	for _, c := range persistent {
+		if !e.IsAlive() {
+			return
+		}
+		executeStep(gs, NewPersistentDamageStep(e, c), fmt.Sprintf("%s takes %s damage.", e.Name, c.Damage.String()))
+		roll := *c.Damage
+		roll.Persistent = false
+		Deal(gs, rollDamage(gs, nil, e, []DamageRoll{roll}))
+		gs.persistentRecovery(e, c, persistentRecoveryDC)
+	}
+}
+
+// persistentRecovery has the entity attempt a flat check to end persistent damage
+func (gs *GameState) persistentRecovery(e *Entity, c Condition, dc int) {
+	check := ResolveCheck(gs, &Check{Roller: e, Name: "Recovery from " + c.Damage.String(), DC: dc})
+	if check.Degree >= Success {
+		e.RemovePersistentDamage(c.Damage.Type)
+		gs.Printf("%s is no longer taking persistent %s damage.\n", e.Name, displayName(string(c.Damage.Type)))
+	}
+}
+
+// NewAssistRecoveryCard creates an activity that helps an adjacent creature put
+// out flames, staunch bleeding and the like, letting it attempt a flat check
+// against a lower DC to end each of its persistent damage right away
+func NewAssistRecoveryCard() *ActionCard {
+	card := NewSingleTargetActionCard(
+		"Assist Recovery",
+		TwoActionCard,
+		"Help an adjacent creature recover from persistent damage.",
+		[]TargetCriterion{
+			IsAlive(),
+			Range(5),
+		},
+		func(gs *GameState, actor *Entity, target *Entity) {
+			var persistent []Condition
+			for _, c := range target.Conditions {
+				if c.Name == PersistentDamage {
+					persistent = append(persistent, c)
+				}
+			}
+			if len(persistent) == 0 {
+				gs.Printf("%s has no persistent damage to recover from.\n", target.Name)
+				return
+			}
+			for _, c := range persistent {
+				gs.persistentRecovery(target, c, assistedRecoveryDC)
+			}
+		},
+	)
+	card.Traits = []ActionTrait{ManipulateTrait}
+	return card
+}
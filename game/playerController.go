@@ -9,6 +9,9 @@ import (
 type PlayerController struct {
 	GameState  *GameState
 	ActionChan chan Action // Channel for receiving actions
+	// Prompter asks the player whether to take reactions. Without one the
+	// player always takes the first reaction on offer.
+	Prompter ReactionPrompter
 }
 
 // NewPlayerController initializes a PlayerController
@@ -24,6 +27,19 @@ func (p *PlayerController) NextAction(gs *GameState, e *Entity) Action {
 	return <-p.ActionChan
 }
 
+// ChooseReaction asks the player whether to react, declining if they don't answer
+func (p *PlayerController) ChooseReaction(gs *GameState, e *Entity, req ReactionRequest) int {
+	if p.Prompter == nil {
+		return 0
+	}
+	choice, err := p.Prompter.RequestReaction(req)
+	if err != nil {
+		gs.Printf("No reaction chosen for %s (%v).\n", e.Name, err)
+		return DeclineReaction
+	}
+	return choice
+}
+
 type ErrNotEntityTurn struct {
 	EntityId uuid.UUID
 }
@@ -0,0 +1,189 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	dice "pf2eEngine/util"
+	"strings"
+)
+
+// maxRangeIncrements is how many range increments away a ranged Strike can reach
+const maxRangeIncrements = 6
+
+// Common ranged weapons
+var (
+	Shortbow = Weapon{Name: "Shortbow", DamageDie: 6, DamageType: Piercing, Group: BowGroup, Hands: 2, Range: 60,
+		Ammunition: "Arrows", Traits: []WeaponTrait{{Name: Deadly, Die: 10}}}
+	Longbow = Weapon{Name: "Longbow", DamageDie: 8, DamageType: Piercing, Group: BowGroup, Hands: 2, Range: 100,
+		Ammunition: "Arrows", Traits: []WeaponTrait{{Name: Deadly, Die: 10}, {Name: Volley, Feet: 30}}}
+	Crossbow = Weapon{Name: "Crossbow", DamageDie: 8, DamageType: Piercing, Group: BowGroup, Hands: 2, Range: 120,
+		Ammunition: "Bolts", Reload: 1}
+	Sling = Weapon{Name: "Sling", DamageDie: 6, DamageType: Bludgeoning, Group: SlingGroup, Hands: 1, Range: 50,
+		Ammunition: "Sling Bullets", Reload: 1}
+	Javelin = Weapon{Name: "Javelin", DamageDie: 6, DamageType: Piercing, Group: SpearGroup, Hands: 1, Range: 30,
+		Traits: []WeaponTrait{{Name: Thrown}}}
+)
+
+// strikePlan is how a Strike with a weapon reaches its target
+type strikePlan struct {
+	ranged    bool // Made at range, with a ranged weapon or by throwing a melee weapon
+	thrown    bool // The weapon leaves the attacker's hand
+	increment int  // Range increment in feet of a ranged Strike
+	distance  int  // Distance to the target in feet
+}
+
+// rangeIncrement is the weapon's range increment in feet when used at range:
+// its own for ranged weapons or its thrown distance for melee weapons
+func (w Weapon) rangeIncrement() int {
+	if w.IsRanged() {
+		return w.Range
+	}
+	if thrown, ok := w.Trait(Thrown); ok {
+		return thrown.Feet
+	}
+	return 0
+}
+
+// maxDistance is the furthest away in feet the weapon can Strike
+func (w Weapon) maxDistance() int {
+	if w.IsRanged() {
+		return w.Range * maxRangeIncrements
+	}
+	return max(w.ReachFeet(), w.rangeIncrement()*maxRangeIncrements)
+}
+
+// planStrike works out how the attacker can Strike the defender with the
+// weapon: in melee if the defender is within reach, otherwise at range. It
+// fails if the target is too far away or a ranged weapon can't be fired.
+func planStrike(gs *GameState, held *WieldedWeapon, attacker *Entity, defender *Entity) (strikePlan, error) {
+	w := held.Weapon
+	plan := strikePlan{distance: gs.Grid.CalculateDistanceBetweenEntities(attacker, defender)}
+	if !gs.Grid.HasLineOfEffect(gs.Grid.GetEntityPosition(attacker), gs.Grid.GetEntityPosition(defender)) {
+		return plan, errors.New("target is not in line of effect")
+	}
+	if !w.IsRanged() && plan.distance <= w.ReachFeet() {
+		return plan, nil
+	}
+	plan.increment = w.rangeIncrement()
+	if plan.increment == 0 {
+		return plan, fmt.Errorf("target is out of reach (max: %d)", w.ReachFeet())
+	}
+	if plan.distance > plan.increment*maxRangeIncrements {
+		return plan, fmt.Errorf("target is out of range (max: %d)", plan.increment*maxRangeIncrements)
+	}
+	plan.ranged = true
+	plan.thrown = w.HasTrait(Thrown)
+	if w.Ammunition != "" && attacker.Ammunition[w.Ammunition] <= 0 {
+		return plan, fmt.Errorf("%s is out of %s", attacker.Name, strings.ToLower(w.Ammunition))
+	}
+	if w.Reload > 0 && !held.Loaded {
+		return plan, fmt.Errorf("%s is not loaded", w.Name)
+	}
+	return plan, nil
+}
+
+// rangeModifiers are the penalties to a ranged Strike: -2 for each range
+// increment beyond the first and -2 within a volley weapon's minimum range
+func (plan strikePlan) rangeModifiers(w Weapon) []dice.Modifier {
+	if !plan.ranged {
+		return nil
+	}
+	var modifiers []dice.Modifier
+	if increments := (plan.distance - 1) / plan.increment; increments > 0 {
+		modifiers = append(modifiers, dice.Modifier{Source: "range", Value: -2 * increments})
+	}
+	if volley, ok := w.Trait(Volley); ok && plan.distance <= volley.Feet {
+		modifiers = append(modifiers, dice.Modifier{Source: "volley", Value: -2})
+	}
+	return modifiers
+}
+
+// fire spends the ammunition and load of a ranged Strike, and lets go of a thrown weapon
+func (plan strikePlan) fire(gs *GameState, held *WieldedWeapon, attacker *Entity) {
+	w := held.Weapon
+	if !plan.ranged {
+		return
+	}
+	if w.Ammunition != "" {
+		attacker.Ammunition[w.Ammunition]--
+	}
+	if w.Reload > 0 {
+		held.Loaded = false
+	}
+	if plan.thrown {
+		attacker.Unwield(w.Name)
+		gs.Printf("%s throws their %s.\n", attacker.Name, strings.ToLower(w.Name))
+	}
+}
+
+// AddAmmunition gives the entity more ammunition of a kind, e.g. 20 "Arrows"
+func (e *Entity) AddAmmunition(kind string, amount int) {
+	if e.Ammunition == nil {
+		e.Ammunition = make(map[string]int)
+	}
+	e.Ammunition[kind] += amount
+}
+
+// copyAmmunition copies an ammunition count so copies of an entity don't share it
+func copyAmmunition(ammunition map[string]int) map[string]int {
+	if ammunition == nil {
+		return nil
+	}
+	c := make(map[string]int, len(ammunition))
+	for kind, n := range ammunition {
+		c[kind] = n
+	}
+	return c
+}
+
+// wielded returns the weapon the entity is wielding with the given name, if any
+func (e *Entity) wielded(name string) *WieldedWeapon {
+	for i := range e.Weapons {
+		if e.Weapons[i].Weapon.Name == name {
+			return &e.Weapons[i]
+		}
+	}
+	return nil
+}
+
+// NewReloadCard creates the Interact action to load a wielded weapon with a
+// reload value, such as a crossbow, before it can be fired again
+func NewReloadCard(w Weapon) *ActionCard {
+	cardType := OneActionCard
+	switch w.Reload {
+	case 2:
+		cardType = TwoActionCard
+	case 3:
+		cardType = ThreeActionCard
+	}
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        fmt.Sprintf("Reload (%s)", w.Name),
+		Type:        cardType,
+		Description: fmt.Sprintf("Load your %s so it can be fired.", strings.ToLower(w.Name)),
+		Traits:      []ActionTrait{ManipulateTrait},
+		weapon:      &WieldedWeapon{Weapon: w},
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			held := actor.wielded(w.Name)
+			switch {
+			case held == nil:
+				return Action{}, fmt.Errorf("actor is not wielding %s", w.Name)
+			case held.Loaded:
+				return Action{}, errors.New("weapon is already loaded")
+			case w.Ammunition != "" && actor.Ammunition[w.Ammunition] <= 0:
+				return Action{}, fmt.Errorf("actor is out of %s", strings.ToLower(w.Ammunition))
+			}
+			return Action{
+				Name: "Reload",
+				Cost: w.Reload,
+				perform: func(gs *GameState, actor *Entity) {
+					if held := actor.wielded(w.Name); held != nil {
+						held.Loaded = true
+						gs.Printf("%s reloads their %s.\n", actor.Name, strings.ToLower(w.Name))
+					}
+				},
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package game
+
+import (
+	"github.com/google/uuid"
+)
+
+// ReactionTrigger is a trigger that spends an entity's reaction. Rather than firing
+// automatically, the entity's controller chooses whether to take it.
+type ReactionTrigger interface {
+	Trigger
+	Reactor() *Entity     // The entity whose reaction it is
+	ReactionName() string // e.g. "Shield Block"
+}
+
+// CopyableReaction is a ReactionTrigger that can be given to another entity,
+// so that a copy of a game hands each copied entity its own reactions
+type CopyableReaction interface {
+	ReactionTrigger
+	WithReactor(e *Entity) ReactionTrigger
+}
+
+// copyTriggers copies a game's triggers for a copy of the game, handing
+// reactions to the copied entity with the same ID. Other triggers are shared.
+func copyTriggers(triggers map[StepType][]Trigger, entities []*Entity) map[StepType][]Trigger {
+	if triggers == nil {
+		return nil
+	}
+	byID := map[uuid.UUID]*Entity{}
+	for _, e := range entities {
+		byID[e.Id] = e
+	}
+	copied := make(map[StepType][]Trigger, len(triggers))
+	for t, list := range triggers {
+		copied[t] = make([]Trigger, len(list))
+		for i, trigger := range list {
+			if reaction, ok := trigger.(CopyableReaction); ok && reaction.Reactor() != nil {
+				if e, ok := byID[reaction.Reactor().Id]; ok {
+					trigger = reaction.WithReactor(e)
+				}
+			}
+			copied[t][i] = trigger
+		}
+	}
+	return copied
+}
+
+// ReactionRequest asks an entity's controller whether to take one of the
+// reactions a step has made available to it
+type ReactionRequest struct {
+	ID      uuid.UUID
+	Entity  *Entity
+	Trigger string   // What happened, e.g. "Goblin 1 is about to deal damage to Warrior."
+	Options []string // The reactions the entity can take, e.g. "Shield Block"
+}
+
+// DeclineReaction is the choice of not taking any reaction
+const DeclineReaction = -1
+
+// ReactionPrompter asks the client controlling an entity whether to take a reaction
+type ReactionPrompter interface {
+	RequestReaction(req ReactionRequest) (int, error)
+}
+
+// runTriggers executes the triggers whose conditions the step meets, in
+// priority order. Reactions are offered to their entity's controller first,
+// together with any other reactions the entity could take to the same step;
+// the entity takes at most one of them.
+func (gs *GameState) runTriggers(step Step, logMessage string) {
+	asked := map[*Entity]bool{}
+	for _, trigger := range gs.Triggers[step.Type()] {
+		reaction, ok := trigger.(ReactionTrigger)
+		if !ok {
+			if trigger.Condition(gs, step) {
+				trigger.Execute(gs, step)
+			}
+			continue
+		}
+
+		reactor := reaction.Reactor()
+		if asked[reactor] {
+			continue
+		}
+		options := gs.availableReactions(step, reactor)
+		if len(options) == 0 {
+			continue
+		}
+		asked[reactor] = true
+		if chosen := gs.chooseReaction(reactor, options, logMessage); chosen != nil {
+			chosen.Execute(gs, step)
+		}
+	}
+}
+
+// availableReactions lists the reactions the step lets the entity take
+func (gs *GameState) availableReactions(step Step, reactor *Entity) []ReactionTrigger {
+	var options []ReactionTrigger
+	for _, trigger := range gs.Triggers[step.Type()] {
+		if reaction, ok := trigger.(ReactionTrigger); ok && reaction.Reactor() == reactor && reaction.Condition(gs, step) {
+			options = append(options, reaction)
+		}
+	}
+	return options
+}
+
+// chooseReaction asks the reactor's controller which of the reactions to take,
+// returning nil if it declines
+func (gs *GameState) chooseReaction(reactor *Entity, options []ReactionTrigger, trigger string) ReactionTrigger {
+	req := ReactionRequest{ID: uuid.New(), Entity: reactor, Trigger: trigger}
+	for _, option := range options {
+		req.Options = append(req.Options, option.ReactionName())
+	}
+
+	// Entities without a controller, such as those in a replayed initial state, always react
+	choice := 0
+	if reactor.Controller != nil {
+		choice = reactor.Controller.ChooseReaction(gs, reactor, req)
+	}
+	if choice < 0 || choice >= len(options) {
+		gs.Printf("%s declines to react.\n", reactor.Name)
+		return nil
+	}
+	return options[choice]
+}
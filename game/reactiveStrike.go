@@ -0,0 +1,47 @@
+package game
+
+import (
+	"errors"
+)
+
+// reactiveStrikeWeapon returns a wielded melee weapon the entity can reach the target with, if any
+func (e *Entity) reactiveStrikeWeapon(gs *GameState, target *Entity) *WieldedWeapon {
+	distance := gs.Grid.CalculateDistanceBetweenEntities(e, target)
+	for i := range e.Weapons {
+		if w := e.Weapons[i].Weapon; !w.IsRanged() && distance <= w.ReachFeet() {
+			return &e.Weapons[i]
+		}
+	}
+	return nil
+}
+
+// CanReactiveStrike reports whether the entity can make a Reactive Strike
+// against the target: it must be conscious with a reaction left, and the
+// target must be a conscious enemy in its line of effect and within reach of
+// one of its melee weapons
+func (e *Entity) CanReactiveStrike(gs *GameState, target *Entity) bool {
+	if e == target || !e.IsConscious() || e.ReactionsRemaining == 0 {
+		return false
+	}
+	if target.Faction == e.Faction || !target.IsConscious() {
+		return false
+	}
+	if !gs.Grid.HasLineOfEffect(gs.Grid.GetEntityPosition(e), gs.Grid.GetEntityPosition(target)) {
+		return false
+	}
+	return e.reactiveStrikeWeapon(gs, target) != nil
+}
+
+// ReactiveStrike has the entity use its reaction to Strike the target with a
+// melee weapon. Being a reaction, the Strike takes no multiple attack penalty
+// and doesn't add to it. It returns the attack so the caller can disrupt the
+// triggering action on a critical hit.
+func ReactiveStrike(gs *GameState, e *Entity, target *Entity) (*Attack, error) {
+	if !e.CanReactiveStrike(gs, target) {
+		return nil, errors.New("cannot make a Reactive Strike against this target")
+	}
+	held := e.reactiveStrikeWeapon(gs, target)
+	e.UseReaction()
+	gs.Printf("%s makes a Reactive Strike against %s with their %s.\n", e.Name, target.Name, held.Weapon.Name)
+	return PerformStrike(gs, held, held.Weapon.DamageType, e, target), nil
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"sort"
+)
+
+// Categories of damage that resistance, weakness and immunity tables can be
+// keyed by as well as by single damage types. Damage is never dealt as one
+// of these.
+const (
+	AllDamage      DamageType = "ALL"
+	PhysicalDamage DamageType = "PHYSICAL"
+	EnergyDamage   DamageType = "ENERGY"
+
+	// PrecisionDamage keys immunity to precision damage, such as sneak
+	// attack, which is dealt as the weapon's own damage type
+	PrecisionDamage DamageType = "PRECISION"
+)
+
+// damageCategory returns the broad category a damage type belongs to, if any
+func damageCategory(t DamageType) (DamageType, bool) {
+	switch t {
+	case Bludgeoning, Piercing, Slashing, Bleed:
+		return PhysicalDamage, true
+	case Acid, Cold, Electricity, Fire, Sonic, Force, Vitality, Void:
+		return EnergyDamage, true
+	default:
+		return "", false
+	}
+}
+
+// appliesTo lists the table keys that cover a damage type, most specific first
+func appliesTo(t DamageType) []DamageType {
+	keys := []DamageType{t}
+	if category, ok := damageCategory(t); ok {
+		keys = append(keys, category)
+	}
+	return append(keys, AllDamage)
+}
+
+// AdjustmentKind says how a damage adjustment changed the damage
+type AdjustmentKind string
+
+const (
+	ImmunityAdjustment   AdjustmentKind = "IMMUNITY"
+	WeaknessAdjustment   AdjustmentKind = "WEAKNESS"
+	ResistanceAdjustment AdjustmentKind = "RESISTANCE"
+)
+
+// DamageAdjustment records an immunity, weakness or resistance changing damage
+type DamageAdjustment struct {
+	Type    DamageType // The damage that was adjusted
+	Kind    AdjustmentKind
+	Against DamageType // The table entry that applied, e.g. FIRE or PHYSICAL
+	Value   int        // Change to the damage: positive for weakness, negative otherwise
+}
+
+// SetResistance gives the entity resistance to a damage type or category
+func (e *Entity) SetResistance(t DamageType, value int) {
+	if e.Resistances == nil {
+		e.Resistances = make(map[DamageType]int)
+	}
+	e.Resistances[t] = value
+}
+
+// SetWeakness gives the entity weakness to a damage type or category
+func (e *Entity) SetWeakness(t DamageType, value int) {
+	if e.Weaknesses == nil {
+		e.Weaknesses = make(map[DamageType]int)
+	}
+	e.Weaknesses[t] = value
+}
+
+// AddImmunity makes the entity immune to a damage type or category
+func (e *Entity) AddImmunity(t DamageType) {
+	if e.Immunities == nil {
+		e.Immunities = make(map[DamageType]bool)
+	}
+	e.Immunities[t] = true
+}
+
+// highest returns the highest value in the table that covers the damage type
+func highest(table map[DamageType]int, t DamageType) (int, DamageType) {
+	best, against := 0, DamageType("")
+	for _, key := range appliesTo(t) {
+		if value := table[key]; value > best {
+			best, against = value, key
+		}
+	}
+	return best, against
+}
+
+// adjustDamage applies the target's immunities, then weaknesses, then
+// resistances to each type of damage. Only the highest weakness and the
+// highest resistance that apply to a type count. Immunity to precision
+// removes just the precision part of each type.
+func (e *Entity) adjustDamage(damage *Damage) {
+	for _, t := range damageTypesOf(damage.Amount) {
+		amount := damage.Amount[t]
+		if precision := min(amount.Precision, amount.Amount); precision > 0 && e.Immunities[PrecisionDamage] {
+			damage.Adjustments = append(damage.Adjustments, DamageAdjustment{Type: t, Kind: ImmunityAdjustment, Against: PrecisionDamage, Value: -precision})
+			amount.Amount -= precision
+			amount.Precision = 0
+			damage.Amount[t] = amount
+		}
+		if amount.Amount <= 0 {
+			continue
+		}
+
+		immune := false
+		for _, key := range appliesTo(t) {
+			if e.Immunities[key] {
+				damage.Adjustments = append(damage.Adjustments, DamageAdjustment{Type: t, Kind: ImmunityAdjustment, Against: key, Value: -amount.Amount})
+				amount.Amount = 0
+				immune = true
+				break
+			}
+		}
+		if !immune {
+			if weakness, against := highest(e.Weaknesses, t); weakness > 0 {
+				damage.Adjustments = append(damage.Adjustments, DamageAdjustment{Type: t, Kind: WeaknessAdjustment, Against: against, Value: weakness})
+				amount.Amount += weakness
+			}
+			if resistance, against := highest(e.Resistances, t); resistance > 0 {
+				resisted := min(resistance, amount.Amount)
+				damage.Adjustments = append(damage.Adjustments, DamageAdjustment{Type: t, Kind: ResistanceAdjustment, Against: against, Value: -resisted})
+				amount.Amount -= resisted
+			}
+		}
+		damage.Amount[t] = amount
+	}
+}
+
+// damageTypesOf returns the damage types in the amount in a stable order
+func damageTypesOf(amount map[DamageType]DamageAmount) []DamageType {
+	types := make([]DamageType, 0, len(amount))
+	for t := range amount {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
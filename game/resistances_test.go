@@ -0,0 +1,90 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+	"reflect"
+	"testing"
+)
+
+func TestAdjustDamage(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(e *Entity)
+		amount      map[DamageType]int
+		want        map[DamageType]int
+		adjustments []DamageAdjustment
+	}{
+		{"no defences", func(e *Entity) {}, map[DamageType]int{Fire: 6}, map[DamageType]int{Fire: 6}, nil},
+		{"resistance", func(e *Entity) { e.SetResistance(Fire, 5) }, map[DamageType]int{Fire: 8}, map[DamageType]int{Fire: 3},
+			[]DamageAdjustment{{Type: Fire, Kind: ResistanceAdjustment, Against: Fire, Value: -5}}},
+		{"resistance can't go below 0", func(e *Entity) { e.SetResistance(Fire, 5) }, map[DamageType]int{Fire: 3}, map[DamageType]int{Fire: 0},
+			[]DamageAdjustment{{Type: Fire, Kind: ResistanceAdjustment, Against: Fire, Value: -3}}},
+		{"weakness", func(e *Entity) { e.SetWeakness(Cold, 3) }, map[DamageType]int{Cold: 4}, map[DamageType]int{Cold: 7},
+			[]DamageAdjustment{{Type: Cold, Kind: WeaknessAdjustment, Against: Cold, Value: 3}}},
+		{"weakness applies before resistance", func(e *Entity) {
+			e.SetWeakness(Fire, 3)
+			e.SetResistance(Fire, 5)
+		}, map[DamageType]int{Fire: 2}, map[DamageType]int{Fire: 0}, []DamageAdjustment{
+			{Type: Fire, Kind: WeaknessAdjustment, Against: Fire, Value: 3},
+			{Type: Fire, Kind: ResistanceAdjustment, Against: Fire, Value: -5},
+		}},
+		{"immunity beats weakness and resistance", func(e *Entity) {
+			e.AddImmunity(Fire)
+			e.SetWeakness(Fire, 3)
+			e.SetResistance(Fire, 5)
+		}, map[DamageType]int{Fire: 6}, map[DamageType]int{Fire: 0},
+			[]DamageAdjustment{{Type: Fire, Kind: ImmunityAdjustment, Against: Fire, Value: -6}}},
+		{"physical resistance covers slashing", func(e *Entity) { e.SetResistance(PhysicalDamage, 2) }, map[DamageType]int{Slashing: 6}, map[DamageType]int{Slashing: 4},
+			[]DamageAdjustment{{Type: Slashing, Kind: ResistanceAdjustment, Against: PhysicalDamage, Value: -2}}},
+		{"physical resistance doesn't cover fire", func(e *Entity) { e.SetResistance(PhysicalDamage, 2) }, map[DamageType]int{Fire: 6}, map[DamageType]int{Fire: 6}, nil},
+		{"energy weakness covers electricity", func(e *Entity) { e.SetWeakness(EnergyDamage, 2) }, map[DamageType]int{Electricity: 3}, map[DamageType]int{Electricity: 5},
+			[]DamageAdjustment{{Type: Electricity, Kind: WeaknessAdjustment, Against: EnergyDamage, Value: 2}}},
+		{"energy immunity doesn't cover piercing", func(e *Entity) { e.AddImmunity(EnergyDamage) }, map[DamageType]int{Piercing: 5}, map[DamageType]int{Piercing: 5}, nil},
+		{"all-damage resistance", func(e *Entity) { e.SetResistance(AllDamage, 1) }, map[DamageType]int{Poison: 4}, map[DamageType]int{Poison: 3},
+			[]DamageAdjustment{{Type: Poison, Kind: ResistanceAdjustment, Against: AllDamage, Value: -1}}},
+		{"highest resistance that applies wins", func(e *Entity) {
+			e.SetResistance(Slashing, 2)
+			e.SetResistance(PhysicalDamage, 4)
+			e.SetResistance(AllDamage, 1)
+		}, map[DamageType]int{Slashing: 6}, map[DamageType]int{Slashing: 2},
+			[]DamageAdjustment{{Type: Slashing, Kind: ResistanceAdjustment, Against: PhysicalDamage, Value: -4}}},
+		{"highest weakness that applies wins", func(e *Entity) {
+			e.SetWeakness(Cold, 5)
+			e.SetWeakness(EnergyDamage, 2)
+		}, map[DamageType]int{Cold: 1}, map[DamageType]int{Cold: 6},
+			[]DamageAdjustment{{Type: Cold, Kind: WeaknessAdjustment, Against: Cold, Value: 5}}},
+		{"category immunity", func(e *Entity) { e.AddImmunity(PhysicalDamage) }, map[DamageType]int{Bludgeoning: 9}, map[DamageType]int{Bludgeoning: 0},
+			[]DamageAdjustment{{Type: Bludgeoning, Kind: ImmunityAdjustment, Against: PhysicalDamage, Value: -9}}},
+	}
+	for _, tt := range tests {
+		target := NewEntity("Target", 30, 15, BadGuys)
+		tt.setup(target)
+		damage := damageOf(tt.amount)
+		target.adjustDamage(&damage)
+		if got := amountsOf(damage); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: damage = %v, want %v", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(damage.Adjustments, tt.adjustments) {
+			t.Errorf("%s: adjustments = %+v, want %+v", tt.name, damage.Adjustments, tt.adjustments)
+		}
+	}
+}
+
+// TestMixedDamageHit checks each type of a hit is adjusted on its own before
+// the total comes off the target's HP
+func TestMixedDamageHit(t *testing.T) {
+	target := NewEntity("Troll", 30, 15, BadGuys)
+	target.SetResistance(PhysicalDamage, 3)
+	target.SetWeakness(Fire, 5)
+	target.AddImmunity(Poison)
+	gs := newTestGame([]Spawn{NewSpawn(target, 0, 0)}, dice.NewSeededSource(1))
+
+	damage := damageOf(map[DamageType]int{Slashing: 7, Fire: 2, Poison: 4})
+	damage.Target = target
+	Deal(gs, damage)
+
+	// 7 slashing - 3, 2 fire + 5 and no poison
+	if target.HP != 19 {
+		t.Errorf("troll has %d HP after the hit, want 19", target.HP)
+	}
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"fmt"
+	dice "pf2eEngine/util"
+)
+
+type SaveType string
+
+const (
+	Fortitude SaveType = "FORTITUDE"
+	Reflex    SaveType = "REFLEX"
+	Will      SaveType = "WILL"
+)
+
+// SaveTypes lists the three saving throws
+var SaveTypes = []SaveType{Fortitude, Reflex, Will}
+
+// Save is a saving throw made by an entity against a DC
+type Save struct {
+	Entity    *Entity
+	Source    *Entity // Whoever forced the save; may be nil, e.g. for hazards
+	Type      SaveType
+	DC        int
+	Modifiers []dice.Modifier // Extra modifiers; triggers on BEFORE_SAVE may add to these
+	Roll      int
+	Bonus     int
+	Result    int
+	Degree    DegreeOfSuccess
+	Record    dice.RollRecord
+}
+
+type BeforeSaveStep struct {
+	BaseStep
+	Save *Save
+}
+
+type AfterSaveStep struct {
+	BaseStep
+	Save *Save
+}
+
+func NewBeforeSaveStep(save *Save) BeforeSaveStep {
+	return BeforeSaveStep{
+		BaseStep: BaseStep{
+			StepType: BeforeSave,
+			metadata: map[string]interface{}{
+				"Entity": save.Entity.Name,
+				"Save":   save.Type,
+				"DC":     save.DC,
+			},
+		},
+		Save: save,
+	}
+}
+
+func NewAfterSaveStep(save *Save) AfterSaveStep {
+	return AfterSaveStep{
+		BaseStep: BaseStep{
+			StepType: AfterSave,
+			metadata: map[string]interface{}{
+				"Entity": save.Entity.Name,
+				"Save":   save.Type,
+				"DC":     save.DC,
+				"Roll":   save.Record.String(),
+				"Rolls":  d20Faces(save.Record),
+				"Result": save.Result,
+				"Degree": save.Degree,
+			},
+		},
+		Save: save,
+	}
+}
+
+// SetSaves sets the Fortitude, Reflex and Will modifiers of an entity without attributes
+func (e *Entity) SetSaves(fortitude, reflex, will int) {
+	e.Saves = map[SaveType]int{
+		Fortitude: fortitude,
+		Reflex:    reflex,
+		Will:      will,
+	}
+}
+
+// SaveModifier returns the entity's modifier for a saving throw
+func (e *Entity) SaveModifier(t SaveType) int {
+	return e.Modifier(Statistic(t))
+}
+
+// RollSave has entity attempt a saving throw against dc and returns the outcome.
+// source is whoever forced the save and may be nil.
+func RollSave(gs *GameState, entity *Entity, source *Entity, saveType SaveType, dc int) *Save {
+	save := &Save{Entity: entity, Source: source, Type: saveType, DC: dc}
+	executeStep(gs, NewBeforeSaveStep(save), fmt.Sprintf("%s attempts a %s save against DC %d.", entity.Name, saveName(saveType), save.DC))
+
+	modifiers := append(entity.Breakdown(Statistic(saveType)).DiceModifiers(), save.Modifiers...)
+	save.Record = rollD20(gs, entity, modifiers)
+	save.Roll = d20Face(save.Record)
+	save.Result = save.Record.Total
+	save.Bonus = save.Result - save.Roll
+	save.Degree = calculateDegreeOfSuccess(save.Roll, save.Result, save.DC)
+
+	executeStep(gs, NewAfterSaveStep(save), fmt.Sprintf("%s rolls %s vs DC %d on a %s save: %s.",
+		entity.Name, save.Record.String(), save.DC, saveName(saveType), save.Degree))
+	return save
+}
+
+// BasicSave rolls damage, has the target attempt a basic saving throw and deals
+// the damage the result calls for: none on a critical success, half on a
+// success, full on a failure and double on a critical failure
+func BasicSave(gs *GameState, source *Entity, target *Entity, saveType SaveType, dc int, damage []DamageRoll) *Save {
+	return basicSave(gs, target, saveType, dc, rollDamage(gs, source, target, damage))
+}
+
+// basicSave has the target attempt a basic saving throw against damage that
+// was already rolled, e.g. once for every creature in an area
+func basicSave(gs *GameState, target *Entity, saveType SaveType, dc int, rolled Damage) *Save {
+	save := RollSave(gs, target, rolled.Source, saveType, dc)
+
+	switch save.Degree {
+	case CriticalSuccess:
+		gs.Printf("%s takes no damage.\n", target.Name)
+	case Success:
+		Deal(gs, rolled.Halve())
+	case Failure:
+		Deal(gs, rolled)
+	case CriticalFailure:
+		Deal(gs, rolled.Double())
+	}
+	return save
+}
+
+func saveName(t SaveType) string {
+	switch t {
+	case Fortitude:
+		return "Fortitude"
+	case Reflex:
+		return "Reflex"
+	case Will:
+		return "Will"
+	default:
+		return string(t)
+	}
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// Shield is a shield an entity holds in one hand. Raising it gives a
+// circumstance bonus to AC, and Shield Block uses its hardness to reduce damage.
+type Shield struct {
+	Name            string
+	ACBonus         int // Circumstance bonus to AC while raised
+	Hardness        int // Damage a Shield Block prevents
+	HP              int
+	MaxHP           int
+	BrokenThreshold int  // The shield is broken at or below this HP
+	Raised          bool // Raised until the start of the holder's next turn
+}
+
+// IsBroken reports whether the shield is broken. A broken shield can't be
+// raised or used to Shield Block.
+func (s *Shield) IsBroken() bool {
+	return s.HP <= s.BrokenThreshold
+}
+
+// IsDestroyed reports whether the shield has been destroyed
+func (s *Shield) IsDestroyed() bool {
+	return s.HP <= 0
+}
+
+// String renders the shield for logs, e.g. "Steel Shield (Hardness 5, HP 20/20)"
+func (s *Shield) String() string {
+	return fmt.Sprintf("%s (Hardness %d, HP %d/%d)", s.Name, s.Hardness, s.HP, s.MaxHP)
+}
+
+// copy returns a separate copy of the shield, or nil for no shield
+func (s *Shield) copy() *Shield {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+// Common shields, at full HP
+var (
+	Buckler      = Shield{Name: "Buckler", ACBonus: 1, Hardness: 3, HP: 6, MaxHP: 6, BrokenThreshold: 3}
+	WoodenShield = Shield{Name: "Wooden Shield", ACBonus: 2, Hardness: 3, HP: 12, MaxHP: 12, BrokenThreshold: 6}
+	SteelShield  = Shield{Name: "Steel Shield", ACBonus: 2, Hardness: 5, HP: 20, MaxHP: 20, BrokenThreshold: 10}
+)
+
+// EquipShield takes up a shield in a free hand, replacing any shield already held
+func (e *Entity) EquipShield(s Shield) error {
+	e.Shield = nil
+	if e.freeHands() < 1 {
+		return fmt.Errorf("%s has no free hand for %s", e.Name, s.Name)
+	}
+	e.Shield = &s
+	return nil
+}
+
+// shieldModifiers are the modifiers the entity's shield gives a statistic
+func (e *Entity) shieldModifiers(stat Statistic) []Modifier {
+	if stat != StatAC || e.Shield == nil || !e.Shield.Raised || e.Shield.IsBroken() {
+		return nil
+	}
+	return []Modifier{{Source: e.Shield.Name, Type: CircumstanceModifier, Value: e.Shield.ACBonus}}
+}
+
+// CanShieldBlock reports whether the entity can use Shield Block against the
+// damage: its shield must be raised and unbroken, it must have a reaction
+// left and the damage must be physical damage from an attack
+func (e *Entity) CanShieldBlock(damage *Damage) bool {
+	if e.Shield == nil || !e.Shield.Raised || e.Shield.IsBroken() || e.ReactionsRemaining == 0 {
+		return false
+	}
+	if damage.Target != e || damage.Source == nil {
+		return false
+	}
+	for t, amount := range damage.Amount {
+		if category, _ := damageCategory(t); category == PhysicalDamage && t != Bleed && amount.Amount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ShieldBlock has the entity block damage with its shield, preventing damage
+// equal to the shield's hardness. The shield and the entity both take the rest.
+func ShieldBlock(gs *GameState, e *Entity, damage *Damage) error {
+	if !e.CanShieldBlock(damage) {
+		return errors.New("cannot Shield Block this damage")
+	}
+	e.UseReaction()
+	damage.Blocked += e.Shield.Hardness
+	damage.BlockedBy = e.Shield
+	gs.Printf("%s uses Shield Block with their %s to block %d damage.\n", e.Name, e.Shield.Name, e.Shield.Hardness)
+	return nil
+}
+
+// damageShield deals damage that got through a Shield Block to the shield
+// that blocked it. A destroyed shield is dropped.
+func (gs *GameState) damageShield(e *Entity, s *Shield, amount int) {
+	if amount <= 0 {
+		return
+	}
+	wasBroken := s.IsBroken()
+	s.HP = max(0, s.HP-amount)
+	gs.Printf("%s's %s takes %d damage. Shield HP: %d/%d\n", e.Name, s.Name, amount, s.HP, s.MaxHP)
+	switch {
+	case s.IsDestroyed():
+		gs.Printf("%s's %s is destroyed!\n", e.Name, s.Name)
+		if e.Shield == s {
+			e.Shield = nil
+		}
+	case s.IsBroken() && !wasBroken:
+		s.Raised = false
+		gs.Printf("%s's %s is broken.\n", e.Name, s.Name)
+	}
+}
+
+// NewRaiseAShieldCard creates the action to raise a held shield, gaining its
+// circumstance bonus to AC until the start of the entity's next turn
+func NewRaiseAShieldCard() *ActionCard {
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        "Raise a Shield",
+		Type:        OneActionCard,
+		Description: "Raise your shield to gain its circumstance bonus to AC until the start of your next turn.",
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			switch {
+			case actor.Shield == nil:
+				return Action{}, errors.New("actor has no shield")
+			case actor.Shield.IsBroken():
+				return Action{}, errors.New("actor's shield is broken")
+			case actor.Shield.Raised:
+				return Action{}, errors.New("actor's shield is already raised")
+			}
+			return Action{
+				Name: "Raise a Shield",
+				Cost: 1,
+				perform: func(gs *GameState, actor *Entity) {
+					actor.Shield.Raised = true
+					gs.Printf("%s raises their %s (+%d AC).\n", actor.Name, actor.Shield.Name, actor.Shield.ACBonus)
+				},
+			}, nil
+		},
+	}
+}
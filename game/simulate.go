@@ -0,0 +1,264 @@
+package game
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	dice "pf2eEngine/util"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Scenario describes a combat that can be run many times over
+type Scenario struct {
+	Name       string
+	GridWidth  int
+	GridHeight int
+	// Spawns builds fresh entities for a single combat. It is called once per
+	// combat, possibly from several goroutines at once.
+	Spawns func() []Spawn
+	// Setup optionally prepares a new game, e.g. by registering triggers
+	Setup func(gs *GameState)
+}
+
+// NewGame creates a game for the scenario whose rolls are determined by seed
+func (s Scenario) NewGame(seed int64) *GameState {
+	return s.newGame(seed, os.Stdout)
+}
+
+func (s Scenario) newGame(seed int64, out io.Writer) *GameState {
+	gs := newGameState(s.Spawns(), s.GridWidth, s.GridHeight, dice.NewSeededSource(seed), out)
+	if s.Setup != nil {
+		s.Setup(gs)
+	}
+	return gs
+}
+
+// SimulationOptions controls a batch of headless combats
+type SimulationOptions struct {
+	Combats   int   // Number of combats to run
+	Seed      int64 // Seed of the first combat; combat i is seeded with Seed+i
+	MaxRounds int   // Combats still running after this many rounds count as draws
+	Workers   int   // Combats run in parallel; zero uses one per CPU
+}
+
+// CombatResult summarises a single headless combat
+type CombatResult struct {
+	Seed        int64
+	Winner      *Faction
+	Rounds      int
+	DamageDealt map[string]int // By entity name
+	DamageTaken map[string]int // By entity name
+	Survivors   map[string]bool
+}
+
+// EntityStats summarises one entity across every combat of a simulation
+type EntityStats struct {
+	Name            string  `json:"name"`
+	Faction         string  `json:"faction"`
+	MeanDamageDealt float64 `json:"meanDamageDealt"`
+	MeanDamageTaken float64 `json:"meanDamageTaken"`
+	SurvivalRate    float64 `json:"survivalRate"`
+}
+
+// RoundStats summarises how long the combats lasted
+type RoundStats struct {
+	Mean         float64     `json:"mean"`
+	Min          int         `json:"min"`
+	Max          int         `json:"max"`
+	Distribution map[int]int `json:"distribution"` // Number of combats that lasted each number of rounds
+}
+
+// SimulationReport aggregates the results of a simulation
+type SimulationReport struct {
+	Scenario string             `json:"scenario"`
+	Combats  int                `json:"combats"`
+	Seed     int64              `json:"seed"`
+	Wins     map[string]int     `json:"wins"`
+	WinRate  map[string]float64 `json:"winRate"`
+	Draws    int                `json:"draws"`
+	Rounds   RoundStats         `json:"rounds"`
+	Entities []EntityStats      `json:"entities"`
+}
+
+// Simulate runs the scenario's combat many times with no delays or console
+// output and reports who won, how long it took and how much damage was done.
+// The report only depends on the scenario and options, not on scheduling.
+func Simulate(scenario Scenario, opts SimulationOptions) SimulationReport {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]CombatResult, opts.Combats)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = SimulateCombat(scenario, opts.Seed+int64(i), opts.MaxRounds)
+			}
+		}()
+	}
+	for i := 0; i < opts.Combats; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return buildReport(scenario, opts, results)
+}
+
+// SimulateCombat runs a single headless combat of the scenario
+func SimulateCombat(scenario Scenario, seed int64, maxRounds int) CombatResult {
+	gs := scenario.newGame(seed, io.Discard)
+	RunCombatWithOptions(gs, CombatOptions{MaxRounds: maxRounds})
+
+	result := CombatResult{
+		Seed:        seed,
+		Winner:      gs.Winner,
+		Rounds:      gs.Round,
+		DamageDealt: map[string]int{},
+		DamageTaken: map[string]int{},
+		Survivors:   map[string]bool{},
+	}
+	if maxRounds > 0 && result.Rounds > maxRounds {
+		result.Rounds = maxRounds
+	}
+	for _, step := range gs.StepHistory.GetSteps() {
+		if s, ok := step.(AfterDamageStep); ok {
+			if s.Damage.Source != nil { // Hazards deal damage with no source
+				result.DamageDealt[s.Damage.Source.Name] += s.Damage.Taken
+			}
+			result.DamageTaken[s.Damage.Target.Name] += s.Damage.Taken
+		}
+	}
+	for _, e := range gs.Initiative {
+		result.Survivors[e.Name] = e.IsAlive()
+	}
+	return result
+}
+
+func buildReport(scenario Scenario, opts SimulationOptions, results []CombatResult) SimulationReport {
+	report := SimulationReport{
+		Scenario: scenario.Name,
+		Combats:  len(results),
+		Seed:     opts.Seed,
+		Wins:     map[string]int{},
+		WinRate:  map[string]float64{},
+		Rounds:   RoundStats{Distribution: map[int]int{}},
+	}
+	if len(results) == 0 {
+		return report
+	}
+
+	// Entities are reported in spawn order; names identify them across combats
+	index := map[string]int{}
+	for _, spawn := range scenario.Spawns() {
+		index[spawn.Unit.Name] = len(report.Entities)
+		report.Entities = append(report.Entities, EntityStats{
+			Name:    spawn.Unit.Name,
+			Faction: spawn.Unit.Faction.String(),
+		})
+	}
+
+	totalRounds := 0
+	report.Rounds.Min = results[0].Rounds
+	for _, r := range results {
+		if r.Winner == nil {
+			report.Draws++
+		} else {
+			report.Wins[r.Winner.String()]++
+		}
+		totalRounds += r.Rounds
+		report.Rounds.Distribution[r.Rounds]++
+		if r.Rounds < report.Rounds.Min {
+			report.Rounds.Min = r.Rounds
+		}
+		if r.Rounds > report.Rounds.Max {
+			report.Rounds.Max = r.Rounds
+		}
+		for name, i := range index {
+			report.Entities[i].MeanDamageDealt += float64(r.DamageDealt[name])
+			report.Entities[i].MeanDamageTaken += float64(r.DamageTaken[name])
+			if r.Survivors[name] {
+				report.Entities[i].SurvivalRate++
+			}
+		}
+	}
+
+	n := float64(len(results))
+	for _, e := range report.Entities {
+		report.WinRate[e.Faction] = float64(report.Wins[e.Faction]) / n
+	}
+	report.Rounds.Mean = float64(totalRounds) / n
+	for i := range report.Entities {
+		report.Entities[i].MeanDamageDealt /= n
+		report.Entities[i].MeanDamageTaken /= n
+		report.Entities[i].SurvivalRate /= n
+	}
+	return report
+}
+
+// WriteJSON writes the report as indented JSON
+func (r SimulationReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the report as a long-format table of section, name, metric
+// and value, which spreadsheets can pivot however the designer likes
+func (r SimulationReport) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	row := func(section, name, metric string, value interface{}) {
+		out.Write([]string{section, name, metric, fmt.Sprint(value)})
+	}
+
+	row("section", "name", "metric", "value")
+	row("simulation", r.Scenario, "combats", r.Combats)
+	row("simulation", r.Scenario, "seed", r.Seed)
+	row("simulation", r.Scenario, "draws", r.Draws)
+
+	factions := make([]string, 0, len(r.WinRate))
+	for faction := range r.WinRate {
+		factions = append(factions, faction)
+	}
+	sort.Strings(factions)
+	for _, faction := range factions {
+		row("faction", faction, "wins", r.Wins[faction])
+		row("faction", faction, "win_rate", formatFloat(r.WinRate[faction]))
+	}
+
+	row("rounds", "", "mean", formatFloat(r.Rounds.Mean))
+	row("rounds", "", "min", r.Rounds.Min)
+	row("rounds", "", "max", r.Rounds.Max)
+	rounds := make([]int, 0, len(r.Rounds.Distribution))
+	for round := range r.Rounds.Distribution {
+		rounds = append(rounds, round)
+	}
+	sort.Ints(rounds)
+	for _, round := range rounds {
+		row("rounds", strconv.Itoa(round), "combats", r.Rounds.Distribution[round])
+	}
+
+	for _, e := range r.Entities {
+		row("entity", e.Name, "faction", e.Faction)
+		row("entity", e.Name, "mean_damage_dealt", formatFloat(e.MeanDamageDealt))
+		row("entity", e.Name, "mean_damage_taken", formatFloat(e.MeanDamageTaken))
+		row("entity", e.Name, "survival_rate", formatFloat(e.SurvivalRate))
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
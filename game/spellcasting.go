@@ -0,0 +1,285 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tradition is the magical tradition a spellcaster draws on
+type Tradition string
+
+const (
+	Arcane Tradition = "ARCANE"
+	Divine Tradition = "DIVINE"
+	Occult Tradition = "OCCULT"
+	Primal Tradition = "PRIMAL"
+)
+
+// CastingType is how a spellcaster readies its spells
+type CastingType string
+
+const (
+	PreparedCasting    CastingType = "PREPARED"    // Spells are prepared into specific slots ahead of time
+	SpontaneousCasting CastingType = "SPONTANEOUS" // Any spell in the repertoire can be cast with a slot
+)
+
+const (
+	StatSpellAttack Statistic = "SPELL_ATTACK"
+	StatSpellDC     Statistic = "SPELL_DC"
+)
+
+// Spellcasting is an entity's ability to cast spells. Entities with attributes
+// work out their spell attack modifier and spell DC from the key attribute and
+// their spell attack proficiency; other entities use Attack and DC directly.
+type Spellcasting struct {
+	Tradition      Tradition
+	Type           CastingType
+	KeyAttribute   AttributeName
+	Attack         int             // Spell attack modifier of entities without attributes
+	DC             int             // Spell DC of entities without attributes
+	Slots          map[int]int     // Spell slots by rank: left to cast for spontaneous casters, to prepare into for prepared ones
+	Prepared       []PreparedSpell // Spells prepared in slots, for prepared casters
+	Repertoire     []Spell         // Spells cast with slots, for spontaneous casters
+	Cantrips       []Spell         // Cast at will, heightened to half the caster's level
+	FocusSpells    []Spell         // Cast with focus points, heightened like cantrips
+	FocusPoints    int
+	MaxFocusPoints int
+}
+
+// PreparedSpell is a spell prepared in a slot of a rank, which is expended
+// once the spell is cast
+type PreparedSpell struct {
+	Spell    Spell
+	Rank     int
+	Expended bool
+}
+
+// copy returns a separate copy of the spellcasting, or nil for none
+func (sc *Spellcasting) copy() *Spellcasting {
+	if sc == nil {
+		return nil
+	}
+	c := *sc
+	c.Slots = make(map[int]int, len(sc.Slots))
+	for rank, n := range sc.Slots {
+		c.Slots[rank] = n
+	}
+	c.Prepared = append([]PreparedSpell(nil), sc.Prepared...)
+	c.Repertoire = append([]Spell(nil), sc.Repertoire...)
+	c.Cantrips = append([]Spell(nil), sc.Cantrips...)
+	c.FocusSpells = append([]Spell(nil), sc.FocusSpells...)
+	return &c
+}
+
+// SetSpellcasting makes the entity a spellcaster, giving it a Cast a Spell
+// card for each spell it already knows or has prepared
+func (e *Entity) SetSpellcasting(sc Spellcasting) {
+	if sc.Slots == nil {
+		sc.Slots = make(map[int]int)
+	}
+	e.Spellcasting = &sc
+	e.refreshSpells()
+}
+
+// LearnSpell adds a spell to the entity's cantrips, focus spells or
+// repertoire. Prepared casters prepare their ranked spells instead.
+func (e *Entity) LearnSpell(s Spell) error {
+	sc := e.Spellcasting
+	switch {
+	case sc == nil:
+		return fmt.Errorf("%s cannot cast spells", e.Name)
+	case !s.Focus && !s.onList(sc.Tradition):
+		return fmt.Errorf("%s is not a %s spell", s.Name, traditionName(sc.Tradition))
+	case s.Cantrip:
+		sc.Cantrips = append(sc.Cantrips, s)
+	case s.Focus:
+		sc.FocusSpells = append(sc.FocusSpells, s)
+	case sc.Type == PreparedCasting:
+		return fmt.Errorf("%s prepares spells rather than learning them", e.Name)
+	default:
+		sc.Repertoire = append(sc.Repertoire, s)
+	}
+	e.refreshSpells()
+	return nil
+}
+
+// PrepareSpell prepares a spell in a free slot of the given rank, heightening
+// it if the rank is above the spell's own
+func (e *Entity) PrepareSpell(s Spell, rank int) error {
+	sc := e.Spellcasting
+	switch {
+	case sc == nil:
+		return fmt.Errorf("%s cannot cast spells", e.Name)
+	case sc.Type != PreparedCasting:
+		return fmt.Errorf("%s casts spells spontaneously", e.Name)
+	case s.Cantrip || s.Focus:
+		return fmt.Errorf("%s is learned rather than prepared", s.Name)
+	case !s.onList(sc.Tradition):
+		return fmt.Errorf("%s is not a %s spell", s.Name, traditionName(sc.Tradition))
+	case rank < s.Rank:
+		return fmt.Errorf("%s can't be prepared below rank %d", s.Name, s.Rank)
+	}
+	used := 0
+	for _, p := range sc.Prepared {
+		if p.Rank == rank {
+			used++
+		}
+	}
+	if used >= sc.Slots[rank] {
+		return fmt.Errorf("%s has no free rank %d slot", e.Name, rank)
+	}
+	sc.Prepared = append(sc.Prepared, PreparedSpell{Spell: s, Rank: rank})
+	e.refreshSpells()
+	return nil
+}
+
+// refreshSpells replaces the entity's Cast a Spell cards with one per spell it can cast
+func (e *Entity) refreshSpells() {
+	cards := e.ActionCards[:0]
+	for _, card := range e.ActionCards {
+		if card.spell == nil {
+			cards = append(cards, card)
+		}
+	}
+	e.ActionCards = cards
+
+	sc := e.Spellcasting
+	if sc == nil {
+		return
+	}
+	seen := map[string]bool{}
+	add := func(s Spell) {
+		if !seen[s.Name] {
+			seen[s.Name] = true
+			e.AddActionCard(NewCastASpellCard(s))
+		}
+	}
+	for _, s := range sc.Cantrips {
+		add(s)
+	}
+	for _, s := range sc.FocusSpells {
+		add(s)
+	}
+	for _, p := range sc.Prepared {
+		add(p.Spell)
+	}
+	for _, s := range sc.Repertoire {
+		add(s)
+	}
+}
+
+// cantripRank is the rank cantrips and focus spells are heightened to: half
+// the caster's level, rounded up
+func (e *Entity) cantripRank() int {
+	return max(1, (e.Level+1)/2)
+}
+
+// spellSlot is what casting a spell uses up
+type spellSlot struct {
+	rank     int // Rank the spell is cast at
+	prepared int // Index of the prepared spell to expend; -1 if not prepared
+}
+
+// findSlot works out the rank the entity can cast the spell at and what it
+// uses up. rank picks the slot rank; zero picks the lowest available.
+func (e *Entity) findSlot(s Spell, rank int) (spellSlot, error) {
+	sc := e.Spellcasting
+	if sc == nil {
+		return spellSlot{}, errors.New("actor cannot cast spells")
+	}
+	switch {
+	case s.Cantrip:
+		if !knowsSpell(sc.Cantrips, s) {
+			return spellSlot{}, fmt.Errorf("actor doesn't know %s", s.Name)
+		}
+		return spellSlot{rank: e.cantripRank(), prepared: -1}, nil
+	case s.Focus:
+		if !knowsSpell(sc.FocusSpells, s) {
+			return spellSlot{}, fmt.Errorf("actor doesn't know %s", s.Name)
+		}
+		if sc.FocusPoints <= 0 {
+			return spellSlot{}, errors.New("actor has no focus points left")
+		}
+		return spellSlot{rank: e.cantripRank(), prepared: -1}, nil
+	case sc.Type == PreparedCasting:
+		best := -1
+		for i, p := range sc.Prepared {
+			if p.Expended || p.Spell.Name != s.Name || (rank != 0 && p.Rank != rank) {
+				continue
+			}
+			if best < 0 || p.Rank < sc.Prepared[best].Rank {
+				best = i
+			}
+		}
+		if best < 0 {
+			return spellSlot{}, fmt.Errorf("actor has no %s prepared", s.Name)
+		}
+		return spellSlot{rank: sc.Prepared[best].Rank, prepared: best}, nil
+	default:
+		if !knowsSpell(sc.Repertoire, s) {
+			return spellSlot{}, fmt.Errorf("%s is not in actor's repertoire", s.Name)
+		}
+		if rank != 0 {
+			if rank < s.Rank {
+				return spellSlot{}, fmt.Errorf("%s can't be cast below rank %d", s.Name, s.Rank)
+			}
+			if sc.Slots[rank] <= 0 {
+				return spellSlot{}, fmt.Errorf("actor has no rank %d slots left", rank)
+			}
+			return spellSlot{rank: rank, prepared: -1}, nil
+		}
+		for r := s.Rank; r <= maxSpellRank; r++ {
+			if sc.Slots[r] > 0 {
+				return spellSlot{rank: r, prepared: -1}, nil
+			}
+		}
+		return spellSlot{}, fmt.Errorf("actor has no slots left for %s", s.Name)
+	}
+}
+
+// expend uses up the slot or focus point the spell is cast with
+func (e *Entity) expend(s Spell, slot spellSlot) {
+	sc := e.Spellcasting
+	switch {
+	case s.Cantrip:
+	case s.Focus:
+		sc.FocusPoints--
+	case slot.prepared >= 0:
+		sc.Prepared[slot.prepared].Expended = true
+	default:
+		sc.Slots[slot.rank]--
+	}
+}
+
+// Refocus restores a focus point, up to the entity's maximum
+func (e *Entity) Refocus() {
+	if sc := e.Spellcasting; sc != nil && sc.FocusPoints < sc.MaxFocusPoints {
+		sc.FocusPoints++
+	}
+}
+
+// knowsSpell reports whether a spell with the same name is in the list
+func knowsSpell(spells []Spell, s Spell) bool {
+	for _, known := range spells {
+		if known.Name == s.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// SpellAttackBreakdown works out the entity's spell attack modifier
+func (e *Entity) SpellAttackBreakdown() Breakdown {
+	return e.breakdown(StatSpellAttack, true)
+}
+
+// SpellDC returns the DC of the entity's spells
+func (e *Entity) SpellDC() int {
+	return e.DC(StatSpellDC)
+}
+
+// traditionName turns "ARCANE" into "arcane" for messages
+func traditionName(t Tradition) string {
+	return strings.ToLower(string(t))
+}
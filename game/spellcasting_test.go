@@ -0,0 +1,152 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+	"reflect"
+	"testing"
+)
+
+// disruptor disrupts every action as it starts, as a critical Reactive Strike
+// disrupts a manipulate action
+type disruptor struct{}
+
+func (disruptor) Priority() int { return 0 }
+
+func (disruptor) Condition(gs *GameState, step Step) bool {
+	_, ok := step.(StartActionStep)
+	return ok
+}
+
+func (disruptor) Execute(gs *GameState, step Step) {
+	step.(StartActionStep).Action.Disrupted = true
+}
+
+// newCastingTest puts a caster with rank 1 and rank 2 slots next to an ally and an enemy
+func newCastingTest(spells ...Spell) (*GameState, *Entity, *Entity, *Entity) {
+	caster := newCaster("Caster", GoodGuys, spells...)
+	caster.Spellcasting.Slots[2] = 1
+	ally := NewEntity("Ally", 20, 15, GoodGuys)
+	enemy := NewEntity("Enemy", 20, 15, BadGuys)
+	gs := newTestGame([]Spawn{NewSpawn(caster, 0, 0), NewSpawn(ally, 0, 1), NewSpawn(enemy, 1, 0)}, dice.NewSeededSource(1))
+	return gs, caster, ally, enemy
+}
+
+// cast generates the caster's Cast a Spell action for the spell
+func cast(gs *GameState, caster *Entity, s Spell, params map[string]interface{}) (Action, error) {
+	return getActionCardByName(caster, "Cast a Spell ("+s.Name+")").GenerateAction(gs, caster, params)
+}
+
+func TestCastingSpends(t *testing.T) {
+	tests := []struct {
+		name  string
+		spell Spell
+		rank  int
+		slots map[int]int
+		focus int
+	}{
+		{"a spontaneous spell uses its lowest slot", Fear, 0, map[int]int{1: 1, 2: 1}, 1},
+		{"a rank param picks the slot", Fear, 2, map[int]int{1: 2, 2: 0}, 1},
+		{"a focus spell uses a focus point", LayOnHands, 0, map[int]int{1: 2, 2: 1}, 0},
+		{"a cantrip is free", ElectricArc, 0, map[int]int{1: 2, 2: 1}, 1},
+	}
+	for _, tt := range tests {
+		gs, caster, ally, enemy := newCastingTest(tt.spell)
+		target := enemy
+		if tt.spell.Beneficial {
+			target = ally
+		}
+		params := map[string]interface{}{TargetID: target.Id.String()}
+		if tt.rank > 0 {
+			params[SpellRankParam] = tt.rank
+		}
+		action, err := cast(gs, caster, tt.spell, params)
+		if err != nil {
+			t.Fatalf("%s: cannot cast %s: %v", tt.name, tt.spell.Name, err)
+		}
+		ExecuteAction(gs, caster, action)
+		if got := caster.Spellcasting.Slots; !reflect.DeepEqual(got, tt.slots) {
+			t.Errorf("%s: slots left %v, want %v", tt.name, got, tt.slots)
+		}
+		if got := caster.Spellcasting.FocusPoints; got != tt.focus {
+			t.Errorf("%s: %d focus points left, want %d", tt.name, got, tt.focus)
+		}
+	}
+}
+
+func TestCastingWithNothingLeft(t *testing.T) {
+	gs, caster, ally, enemy := newCastingTest(Fear, LayOnHands)
+	sc := caster.Spellcasting
+	sc.Slots = map[int]int{1: 0, 2: 0}
+	sc.FocusPoints = 0
+
+	if _, err := cast(gs, caster, Fear, map[string]interface{}{TargetID: enemy.Id.String()}); err == nil {
+		t.Errorf("cast Fear without a slot")
+	}
+	if _, err := cast(gs, caster, LayOnHands, map[string]interface{}{TargetID: ally.Id.String()}); err == nil {
+		t.Errorf("cast Lay on Hands without a focus point")
+	}
+}
+
+func TestPreparedSpellsAreExpended(t *testing.T) {
+	caster := NewEntity("Wizard", 20, 15, GoodGuys)
+	caster.SetSpellcasting(Spellcasting{Tradition: Arcane, Type: PreparedCasting, Attack: 7, DC: 17, Slots: map[int]int{1: 1, 2: 1}})
+	for _, rank := range []int{2, 1} {
+		if err := caster.PrepareSpell(Fear, rank); err != nil {
+			t.Fatalf("PrepareSpell(Fear, %d) returned error: %v", rank, err)
+		}
+	}
+	enemy := NewEntity("Enemy", 20, 15, BadGuys)
+	gs := newTestGame([]Spawn{NewSpawn(caster, 0, 0), NewSpawn(enemy, 1, 0)}, dice.NewSeededSource(1))
+	params := map[string]interface{}{TargetID: enemy.Id.String()}
+
+	// The lowest prepared copy goes first
+	for _, wantExpended := range [][]bool{{false, true}, {true, true}} {
+		caster.ActionsRemaining = 3
+		action, err := cast(gs, caster, Fear, params)
+		if err != nil {
+			t.Fatalf("cannot cast Fear: %v", err)
+		}
+		ExecuteAction(gs, caster, action)
+		var expended []bool
+		for _, p := range caster.Spellcasting.Prepared {
+			expended = append(expended, p.Expended)
+		}
+		if !reflect.DeepEqual(expended, wantExpended) {
+			t.Errorf("expended %v, want %v", expended, wantExpended)
+		}
+	}
+	if _, err := cast(gs, caster, Fear, params); err == nil {
+		t.Errorf("cast Fear with every copy expended")
+	}
+}
+
+func TestDisruptedCastIsSpent(t *testing.T) {
+	gs, caster, _, enemy := newCastingTest(Fear)
+	gs.RegisterTrigger(disruptor{}, StartAction)
+
+	action, err := cast(gs, caster, Fear, map[string]interface{}{TargetID: enemy.Id.String()})
+	if err != nil {
+		t.Fatalf("cannot cast Fear: %v", err)
+	}
+	ExecuteAction(gs, caster, action)
+	if got := caster.Spellcasting.Slots[1]; got != 1 {
+		t.Errorf("%d rank 1 slots left after a disrupted cast, want 1", got)
+	}
+	if enemy.HasCondition(Frightened) {
+		t.Errorf("a disrupted Fear frightened its target")
+	}
+}
+
+func TestUnaffordableCastIsNotSpent(t *testing.T) {
+	gs, caster, _, enemy := newCastingTest(Fear)
+	caster.ActionsRemaining = 1
+
+	action, err := cast(gs, caster, Fear, map[string]interface{}{TargetID: enemy.Id.String()})
+	if err != nil {
+		t.Fatalf("cannot cast Fear: %v", err)
+	}
+	ExecuteAction(gs, caster, action)
+	if got := caster.Spellcasting.Slots[1]; got != 2 {
+		t.Errorf("%d rank 1 slots left after a cast without enough actions, want 2", got)
+	}
+}
@@ -0,0 +1,486 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	dice "pf2eEngine/util"
+	"strings"
+)
+
+// maxSpellRank is the highest rank of spell and spell slot
+const maxSpellRank = 10
+
+// SpellRankParam picks the slot rank a spell is cast with, heightening it
+const SpellRankParam = "rank"
+
+// Spell defines a spell: what casting it costs, what it targets and how it
+// resolves. A spell with Attack resolves with a spell attack roll against AC,
+// one with Save has the target attempt a save against the caster's spell DC,
+// and Effect applies anything beyond the damage once that is known.
+type Spell struct {
+	Name        string
+	Description string
+	Rank        int  // Lowest rank the spell can be cast at
+	Cantrip     bool // Cast at will and heightened to half the caster's level
+	Focus       bool // Cast with a focus point and heightened like a cantrip
+	Traditions  []Tradition
+	Cost        int               // Actions to cast, 1 to 3
+	MaxCost     int               // Most actions for spells cast with a variable number, e.g. Force Barrage; zero if fixed
+	Traits      []ActionTrait     // Traits of the Cast a Spell action, e.g. concentrate and manipulate
+	Range       int               // Feet to the target; 5 for touch spells
+	Targets     *TargetRules      // Lets the spell target several creatures, each resolved separately; with MaxCost, MaxTargets is per action
+	Attack      bool              // Resolved with a spell attack roll against the target's AC
+	Save        SaveType          // Save the target attempts against the spell DC, if any
+	BasicSave   bool              // The save decides the damage taken: none, half, full or double
+	Damage      string            // Damage at the spell's own rank, e.g. "2d4 fire"
+	Heightened  string            // Damage added for each rank above the spell's own, e.g. "1d4 fire"
+	Area        *Area             // Area the spell covers instead of targeting a creature; Range is how far away a burst can be
+	Square      []SquareCriterion // Set for spells that target a square within Range instead, with checks beyond range and line of effect
+	Beneficial  bool              // Helps its target, e.g. healing, so it is cast on allies rather than enemies
+	Effect      func(gs *GameState, cast *SpellCast)
+}
+
+// SpellCast is a spell being cast at a target, passed to the spell's Effect
+// once its attack or save has been rolled
+type SpellCast struct {
+	Spell  Spell
+	Caster *Entity
+	Target *Entity
+	Rank   int           // Rank the spell is cast at
+	Attack *Attack       // The spell attack, for spells with Attack
+	Save   *Save         // The target's save, for spells with Save
+	Area   *AreaTemplate // Where an area spell was placed
+	Square *Position     // The square a spell with Square was cast at
+}
+
+// onList reports whether the spell belongs to the tradition. Spells without
+// traditions, such as monster abilities, are on every list.
+func (s Spell) onList(t Tradition) bool {
+	if len(s.Traditions) == 0 {
+		return true
+	}
+	for _, tradition := range s.Traditions {
+		if tradition == t {
+			return true
+		}
+	}
+	return false
+}
+
+// damageAt is the spell's damage when cast at a rank, including heightening
+func (s Spell) damageAt(rank int) ([]DamageRoll, error) {
+	if s.Damage == "" {
+		return nil, nil
+	}
+	rolls, err := ParseDamage(s.Damage)
+	if err != nil {
+		return nil, err
+	}
+	if s.Heightened == "" {
+		return rolls, nil
+	}
+	extra, err := ParseDamage(s.Heightened)
+	if err != nil {
+		return nil, err
+	}
+	for r := s.Rank; r < rank; r++ {
+		rolls = append(rolls, extra...)
+	}
+	return rolls, nil
+}
+
+// cardType is the action card type matching the spell's cost
+func (s Spell) cardType() ActionCardType {
+	if s.MaxCost > s.Cost {
+		return VariableActionCard
+	}
+	switch s.Cost {
+	case 2:
+		return TwoActionCard
+	case 3:
+		return ThreeActionCard
+	}
+	return OneActionCard
+}
+
+// NewCastASpellCard creates the Cast a Spell action for a spell, costing the
+// spell's actions and targeting a creature within its range, or an area placed
+// with "origin" and "direction" params for area spells, a square chosen with
+// a "position" param for spells with Square, or creatures chosen with
+// "targetIDs" for spells with Targets. Spells with a variable cost take
+// an "action_cost" param. A "rank" param picks the slot rank; by default the
+// lowest available slot is used.
+func NewCastASpellCard(s Spell) *ActionCard {
+	var targets *TargetRules
+	if s.Targets != nil {
+		// Advertise the most targets the spell can take at any cost
+		rules := s.targetRules(max(s.Cost, s.MaxCost))
+		targets = &rules
+	}
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        fmt.Sprintf("Cast a Spell (%s)", s.Name),
+		Type:        s.cardType(),
+		Description: s.Description,
+		Traits:      s.Traits,
+		Targets:     targets,
+		spell:       &s,
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			rank, err := spellRank(params)
+			if err != nil {
+				return Action{}, err
+			}
+			slot, err := actor.findSlot(s, rank)
+			if err != nil {
+				return Action{}, err
+			}
+			cost := s.Cost
+			if s.MaxCost > s.Cost {
+				if cost, err = variableCost(params, s.Cost, s.MaxCost); err != nil {
+					return Action{}, err
+				}
+			}
+			if s.Area != nil {
+				template, err := areaTemplate(gs, actor, *s.Area, s.Range, params)
+				if err != nil {
+					return Action{}, err
+				}
+				return Action{
+					Name: "Cast a Spell",
+					Cost: cost,
+					pay: func(gs *GameState, actor *Entity) {
+						actor.expend(s, slot)
+					},
+					perform: func(gs *GameState, actor *Entity) {
+						CastAreaSpell(gs, s, slot.rank, actor, template)
+					},
+				}, nil
+			}
+			if s.Square != nil {
+				criteria := append([]SquareCriterion{InBounds(), SquareRange(s.Range), LineOfEffect()}, s.Square...)
+				square, err := getSquare(gs, actor, PositionParam, criteria, params)
+				if err != nil {
+					return Action{}, err
+				}
+				return Action{
+					Name: "Cast a Spell",
+					Cost: cost,
+					pay: func(gs *GameState, actor *Entity) {
+						actor.expend(s, slot)
+					},
+					perform: func(gs *GameState, actor *Entity) {
+						CastSpellAt(gs, s, slot.rank, actor, square)
+					},
+				}, nil
+			}
+			if s.Targets != nil {
+				targets, err := getTargets(gs, actor, s.targetRules(cost), []TargetCriterion{IsAlive()}, params)
+				if err != nil {
+					return Action{}, err
+				}
+				return Action{
+					Name: "Cast a Spell",
+					Cost: cost,
+					pay: func(gs *GameState, actor *Entity) {
+						actor.expend(s, slot)
+					},
+					perform: func(gs *GameState, actor *Entity) {
+						CastSpellOnEach(gs, s, slot.rank, actor, targets)
+					},
+				}, nil
+			}
+			target, err := getSingleTarget(gs, actor, []TargetCriterion{IsAlive(), Range(s.Range), InLineOfEffect()}, params)
+			if err != nil {
+				return Action{}, err
+			}
+			return Action{
+				Name: "Cast a Spell",
+				Cost: cost,
+				pay: func(gs *GameState, actor *Entity) {
+					actor.expend(s, slot)
+				},
+				perform: func(gs *GameState, actor *Entity) {
+					CastSpell(gs, s, slot.rank, actor, target)
+				},
+			}, nil
+		},
+	}
+}
+
+// targetRules are the limits on the targets of a spell with Targets when cast
+// with a number of actions
+func (s Spell) targetRules(cost int) TargetRules {
+	rules := *s.Targets
+	if rules.Range == 0 {
+		rules.Range = s.Range
+	}
+	if s.MaxCost > 0 {
+		rules.MaxTargets *= cost
+	}
+	return rules
+}
+
+// spellRank reads the slot rank chosen for a spell; ranks decoded from JSON arrive as float64
+func spellRank(params map[string]interface{}) (int, error) {
+	switch v := params[SpellRankParam].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case float64:
+		if float64(int(v)) != v {
+			return 0, fmt.Errorf("spell rank must be a whole number, got %v", v)
+		}
+		return int(v), nil
+	default:
+		return 0, errors.New("spell rank must be a number")
+	}
+}
+
+// CastSpell resolves a spell cast at a rank against a target: a spell attack
+// deals its damage on a hit and double on a critical hit, a basic save deals
+// none, half, full or double damage, and the spell's Effect does the rest.
+func CastSpell(gs *GameState, s Spell, rank int, caster *Entity, target *Entity) {
+	gs.Printf("%s casts %s (rank %d) on %s.\n", caster.Name, s.Name, rank, target.Name)
+	damage, err := s.damageAt(rank)
+	if err != nil {
+		gs.Printf("%s has invalid damage: %v\n", s.Name, err)
+		return
+	}
+
+	s.resolve(gs, &SpellCast{Spell: s, Caster: caster, Target: target, Rank: rank}, damage, nil)
+}
+
+// CastSpellAt resolves a spell cast at a rank on a square, leaving everything
+// it does to the spell's Effect
+func CastSpellAt(gs *GameState, s Spell, rank int, caster *Entity, square Position) {
+	gs.Printf("%s casts %s (rank %d) at (%d,%d).\n", caster.Name, s.Name, rank, square.X, square.Y)
+	if s.Effect != nil {
+		s.Effect(gs, &SpellCast{Spell: s, Caster: caster, Rank: rank, Square: &square})
+	}
+}
+
+// CastSpellOnEach resolves a spell cast at a rank against several targets in
+// turn, each with its own attack or save and damage roll. A creature chosen
+// more than once is affected that many times; one already dead is skipped.
+func CastSpellOnEach(gs *GameState, s Spell, rank int, caster *Entity, targets []*Entity) {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	gs.Printf("%s casts %s (rank %d) on %s.\n", caster.Name, s.Name, rank, strings.Join(names, ", "))
+	damage, err := s.damageAt(rank)
+	if err != nil {
+		gs.Printf("%s has invalid damage: %v\n", s.Name, err)
+		return
+	}
+
+	for _, target := range targets {
+		if target.IsAlive() {
+			s.resolve(gs, &SpellCast{Spell: s, Caster: caster, Target: target, Rank: rank}, damage, nil)
+		}
+	}
+}
+
+// CastAreaSpell resolves an area spell cast at a rank, placing its template
+// and affecting every creature inside. Damage is rolled once for all of them;
+// each creature saves separately.
+func CastAreaSpell(gs *GameState, s Spell, rank int, caster *Entity, template AreaTemplate) {
+	gs.Printf("%s casts %s (rank %d) in a %s.\n", caster.Name, s.Name, rank, template.Area)
+	damage, err := s.damageAt(rank)
+	if err != nil {
+		gs.Printf("%s has invalid damage: %v\n", s.Name, err)
+		return
+	}
+
+	targets := PlaceArea(gs, caster, template)
+	var rolled *Damage
+	if len(damage) > 0 && !s.Attack && len(targets) > 0 {
+		r := rollDamage(gs, caster, nil, damage)
+		rolled = &r
+	}
+	for _, target := range targets {
+		s.resolve(gs, &SpellCast{Spell: s, Caster: caster, Target: target, Rank: rank, Area: &template}, damage, rolled)
+	}
+}
+
+// resolve rolls the spell's attack or save against the cast's target and
+// deals its damage, using damage already rolled for an area if there is any
+func (s Spell) resolve(gs *GameState, cast *SpellCast, damage []DamageRoll, rolled *Damage) {
+	caster, target := cast.Caster, cast.Target
+	roll := func() Damage {
+		if rolled != nil {
+			return rolled.copyFor(target)
+		}
+		return rollDamage(gs, caster, target, damage)
+	}
+	switch {
+	case s.Attack:
+		modifiers := append(caster.SpellAttackBreakdown().DiceModifiers(),
+			dice.Modifier{Source: "MAP", Value: multipleAttackPenalty(caster.MapCounter)})
+		cast.Attack = resolveAttack(gs, caster, target, nil, modifiers, func(critical bool) Damage {
+			if critical {
+				return roll().Double()
+			}
+			return roll()
+		})
+	case s.Save != "" && s.BasicSave:
+		cast.Save = basicSave(gs, target, s.Save, caster.SpellDC(), roll())
+	case s.Save != "":
+		cast.Save = RollSave(gs, target, caster, s.Save, caster.SpellDC())
+	case len(damage) > 0:
+		Deal(gs, roll())
+	}
+
+	if s.Effect != nil {
+		s.Effect(gs, cast)
+	}
+}
+
+// Common spells
+var (
+	Ignition = Spell{
+		Name:        "Ignition",
+		Description: "Make a spell attack against a creature within 30 feet to set it alight, dealing 2d4 fire damage.",
+		Cantrip:     true,
+		Rank:        1,
+		Traditions:  []Tradition{Arcane, Primal},
+		Cost:        2,
+		Traits:      []ActionTrait{AttackTrait, ConcentrateTrait, ManipulateTrait},
+		Range:       30,
+		Attack:      true,
+		Damage:      "2d4 fire",
+		Heightened:  "1d4 fire",
+	}
+	ElectricArc = Spell{
+		Name:        "Electric Arc",
+		Description: "An arc of lightning deals 2d4 electricity damage to a creature within 30 feet (basic Reflex save).",
+		Cantrip:     true,
+		Rank:        1,
+		Traditions:  []Tradition{Arcane, Primal},
+		Cost:        2,
+		Traits:      []ActionTrait{ConcentrateTrait, ManipulateTrait},
+		Range:       30,
+		Save:        Reflex,
+		BasicSave:   true,
+		Damage:      "2d4 electricity",
+		Heightened:  "1d4 electricity",
+	}
+	Fear = Spell{
+		Name:        "Fear",
+		Description: "Plant fear in a creature within 30 feet, frightening it unless it succeeds at a Will save.",
+		Rank:        1,
+		Traditions:  []Tradition{Arcane, Divine, Occult, Primal},
+		Cost:        2,
+		Traits:      []ActionTrait{ConcentrateTrait, EmotionTrait, ManipulateTrait, MentalTrait},
+		Range:       30,
+		Save:        Will,
+		Effect: func(gs *GameState, cast *SpellCast) {
+			frightened := 0
+			switch cast.Save.Degree {
+			case Success:
+				frightened = 1
+			case Failure:
+				frightened = 2
+			case CriticalFailure:
+				frightened = 3
+			}
+			if frightened > 0 {
+				cast.Target.AddCondition(Condition{Name: Frightened, Value: frightened, Source: "Fear"})
+				gs.Printf("%s is now %s.\n", cast.Target.Name, Condition{Name: Frightened, Value: frightened})
+			}
+		},
+	}
+	ThunderStrike = Spell{
+		Name:        "Thunderstrike",
+		Description: "Call down lightning on a creature within 120 feet, dealing 1d12 electricity and 1d4 sonic damage (basic Reflex save).",
+		Rank:        1,
+		Traditions:  []Tradition{Arcane, Primal},
+		Cost:        2,
+		Traits:      []ActionTrait{ConcentrateTrait, ManipulateTrait},
+		Range:       120,
+		Save:        Reflex,
+		BasicSave:   true,
+		Damage:      "1d12 electricity+1d4 sonic",
+		Heightened:  "1d12 electricity+1d4 sonic",
+	}
+	BreatheFire = Spell{
+		Name:        "Breathe Fire",
+		Description: "Exhale a 15-foot cone of flame that deals 2d6 fire damage to each creature in it (basic Reflex save).",
+		Rank:        1,
+		Traditions:  []Tradition{Arcane, Primal},
+		Cost:        2,
+		Traits:      []ActionTrait{ConcentrateTrait, ManipulateTrait},
+		Area:        &Area{Shape: Cone, Size: 15},
+		Save:        Reflex,
+		BasicSave:   true,
+		Damage:      "2d6 fire",
+		Heightened:  "2d6 fire",
+	}
+	Fireball = Spell{
+		Name:        "Fireball",
+		Description: "A roaring blast of fire explodes in a 20-foot burst within 500 feet, dealing 6d6 fire damage (basic Reflex save).",
+		Rank:        3,
+		Traditions:  []Tradition{Arcane, Primal},
+		Cost:        2,
+		Traits:      []ActionTrait{ConcentrateTrait, ManipulateTrait},
+		Range:       500,
+		Area:        &Area{Shape: Burst, Size: 20},
+		Save:        Reflex,
+		BasicSave:   true,
+		Damage:      "6d6 fire",
+		Heightened:  "2d6 fire",
+	}
+	LightningBolt = Spell{
+		Name:        "Lightning Bolt",
+		Description: "A bolt of lightning strikes out in a 120-foot line, dealing 4d12 electricity damage (basic Reflex save).",
+		Rank:        3,
+		Traditions:  []Tradition{Arcane, Primal},
+		Cost:        2,
+		Traits:      []ActionTrait{ConcentrateTrait, ManipulateTrait},
+		Area:        &Area{Shape: Line, Size: 120},
+		Save:        Reflex,
+		BasicSave:   true,
+		Damage:      "4d12 electricity",
+		Heightened:  "1d12 electricity",
+	}
+	ForceBarrage = Spell{
+		Name:        "Force Barrage",
+		Description: "Fire a shard of force for each action spent, up to 3, at creatures within 120 feet; each shard deals 1d4+1 force damage.",
+		Rank:        1,
+		Traditions:  []Tradition{Arcane, Occult},
+		Cost:        1,
+		MaxCost:     3,
+		Traits:      []ActionTrait{ConcentrateTrait, ManipulateTrait},
+		Range:       120,
+		Targets:     &TargetRules{MaxTargets: 1},
+		Damage:      "1d4+1 force",
+	}
+	Translocate = Spell{
+		Name:        "Translocate",
+		Description: "Teleport to an unoccupied square within 30 feet that you have line of effect to.",
+		Rank:        2,
+		Traditions:  []Tradition{Arcane, Occult},
+		Cost:        2,
+		Traits:      []ActionTrait{ConcentrateTrait, ManipulateTrait},
+		Range:       30,
+		Square:      []SquareCriterion{Unoccupied()},
+		Effect: func(gs *GameState, cast *SpellCast) {
+			Teleport(gs, cast.Caster, *cast.Square, "Translocate")
+		},
+	}
+	LayOnHands = Spell{
+		Name:        "Lay on Hands",
+		Description: "Touch a creature to heal it 6 HP for each rank of the spell.",
+		Focus:       true,
+		Rank:        1,
+		Cost:        1,
+		Traits:      []ActionTrait{ManipulateTrait},
+		Range:       5,
+		Beneficial:  true,
+		Effect: func(gs *GameState, cast *SpellCast) {
+			Heal(gs, Healing{Source: cast.Caster, Target: cast.Target, Amount: 6 * cast.Rank})
+		},
+	}
+)
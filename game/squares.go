@@ -0,0 +1,158 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// PositionParam chooses the square a square-targeted card acts on, as [x, y]
+const PositionParam = "position"
+
+// positionParam reads a square from params. Squares decoded from JSON arrive
+// as arrays of float64, or as objects with "x" and "y".
+func positionParam(params map[string]interface{}, key string) (Position, error) {
+	switch v := params[key].(type) {
+	case Position:
+		return v, nil
+	case [2]int:
+		return Position{X: v[0], Y: v[1]}, nil
+	case []int:
+		if len(v) == 2 {
+			return Position{X: v[0], Y: v[1]}, nil
+		}
+	case []interface{}:
+		if len(v) == 2 {
+			if x, y, ok := wholeNumbers(v[0], v[1]); ok {
+				return Position{X: x, Y: y}, nil
+			}
+		}
+	case map[string]interface{}:
+		if x, y, ok := wholeNumbers(v["x"], v["y"]); ok {
+			return Position{X: x, Y: y}, nil
+		}
+	case nil:
+		return Position{}, fmt.Errorf("%s not found in params", key)
+	}
+	return Position{}, fmt.Errorf("%s must be a square as [x, y]", key)
+}
+
+// wholeNumbers reads a pair of coordinates decoded from JSON
+func wholeNumbers(a, b interface{}) (int, int, bool) {
+	x, okX := a.(float64)
+	y, okY := b.(float64)
+	if !okX || !okY || x != float64(int(x)) || y != float64(int(y)) {
+		return 0, 0, false
+	}
+	return int(x), int(y), true
+}
+
+// targetIDParam reads the ID of a card's target. IDs decoded from JSON arrive
+// as strings.
+func targetIDParam(params map[string]interface{}) (uuid.UUID, error) {
+	switch v := params[TargetID].(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid target ID %q", v)
+		}
+		return id, nil
+	case nil:
+		return uuid.Nil, errors.New("targetID not found in params")
+	}
+	return uuid.Nil, errors.New("targetID must be an entity ID")
+}
+
+// SquareCriterion checks a square chosen for a card, as TargetCriterion
+// checks a creature
+type SquareCriterion func(gs *GameState, actor *Entity, pos Position) error
+
+// InBounds requires the square to be on the grid
+func InBounds() SquareCriterion {
+	return func(gs *GameState, actor *Entity, pos Position) error {
+		if !gs.Grid.IsValidPosition(pos) {
+			return fmt.Errorf("(%d,%d) is off the grid", pos.X, pos.Y)
+		}
+		return nil
+	}
+}
+
+// Unoccupied requires the square to be free of creatures and walls
+func Unoccupied() SquareCriterion {
+	return func(gs *GameState, actor *Entity, pos Position) error {
+		if gs.Grid.IsWall(pos) {
+			return fmt.Errorf("(%d,%d) is a wall", pos.X, pos.Y)
+		}
+		if e := gs.Grid.GetEntityAt(pos); e != nil {
+			return fmt.Errorf("(%d,%d) is occupied by %s", pos.X, pos.Y, e.Name)
+		}
+		return nil
+	}
+}
+
+// SquareRange requires the square to be within reach or range of the actor, in feet
+func SquareRange(maxDistance int) SquareCriterion {
+	return func(gs *GameState, actor *Entity, pos Position) error {
+		if gs.Grid.CalculateDistance(gs.Grid.GetEntityPosition(actor), pos) > maxDistance {
+			return fmt.Errorf("(%d,%d) is out of range (max: %d)", pos.X, pos.Y, maxDistance)
+		}
+		return nil
+	}
+}
+
+// LineOfEffect requires an unbroken line from the actor's square to the square
+func LineOfEffect() SquareCriterion {
+	return func(gs *GameState, actor *Entity, pos Position) error {
+		if !gs.Grid.HasLineOfEffect(gs.Grid.GetEntityPosition(actor), pos) {
+			return fmt.Errorf("no line of effect to (%d,%d)", pos.X, pos.Y)
+		}
+		return nil
+	}
+}
+
+// getSquare reads the square under key in params and checks it against every criterion
+func getSquare(gs *GameState, actor *Entity, key string, criteria []SquareCriterion, params map[string]interface{}) (Position, error) {
+	pos, err := positionParam(params, key)
+	if err != nil {
+		return Position{}, err
+	}
+	for _, criterion := range criteria {
+		if err := criterion(gs, actor, pos); err != nil {
+			return Position{}, err
+		}
+	}
+	return pos, nil
+}
+
+// NewSquareActionCard creates an action card that acts on a square chosen
+// with a "position" param rather than on a creature, such as moving,
+// teleporting or placing something on the grid
+func NewSquareActionCard(
+	name string,
+	actionType ActionCardType,
+	description string,
+	criteria []SquareCriterion,
+	actionFunc func(gs *GameState, actor *Entity, pos Position),
+) *ActionCard {
+	return &ActionCard{
+		ID:          uuid.New(),
+		Name:        name,
+		Type:        actionType,
+		Description: description,
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			pos, err := getSquare(gs, actor, PositionParam, criteria, params)
+			if err != nil {
+				return Action{}, err
+			}
+			return Action{
+				Name: name,
+				Cost: actionType.ToCost(params),
+				perform: func(gs *GameState, actor *Entity) {
+					actionFunc(gs, actor, pos)
+				},
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,262 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+)
+
+// ModifierType decides how a modifier stacks with others
+type ModifierType string
+
+const (
+	AttributeModifier    ModifierType = "ATTRIBUTE"
+	ProficiencyModifier  ModifierType = "PROFICIENCY"
+	ItemModifier         ModifierType = "ITEM"
+	StatusModifier       ModifierType = "STATUS"
+	CircumstanceModifier ModifierType = "CIRCUMSTANCE"
+	UntypedModifier      ModifierType = "UNTYPED"
+)
+
+// typed modifiers don't stack: only the highest bonus and the worst penalty of
+// each type apply
+func (t ModifierType) typed() bool {
+	return t == ItemModifier || t == StatusModifier || t == CircumstanceModifier
+}
+
+// Modifier is a typed contribution to a statistic
+type Modifier struct {
+	Source string
+	Type   ModifierType
+	Value  int
+}
+
+// Breakdown shows how a statistic's total was reached
+type Breakdown struct {
+	Statistic Statistic
+	Base      int        // 10 for AC and class DC, 0 for modifiers
+	Modifiers []Modifier // Only the modifiers that apply after stacking
+	Total     int
+}
+
+// DiceModifiers converts the breakdown's modifiers for a roll record
+func (b Breakdown) DiceModifiers() []dice.Modifier {
+	modifiers := make([]dice.Modifier, len(b.Modifiers))
+	for i, m := range b.Modifiers {
+		modifiers[i] = dice.Modifier{Source: m.Source, Value: m.Value}
+	}
+	return modifiers
+}
+
+// stackModifiers drops zero modifiers and those beaten by a modifier of the same type
+func stackModifiers(modifiers []Modifier) []Modifier {
+	var applied []Modifier
+	bonuses := map[ModifierType]int{} // Index of the applied bonus of each type
+	penalties := map[ModifierType]int{}
+	for _, m := range modifiers {
+		if m.Value == 0 {
+			continue
+		}
+		if !m.Type.typed() {
+			applied = append(applied, m)
+			continue
+		}
+		best := bonuses
+		if m.Value < 0 {
+			best = penalties
+		}
+		if i, ok := best[m.Type]; ok {
+			if abs(m.Value) > abs(applied[i].Value) {
+				applied[i] = m
+			}
+			continue
+		}
+		best[m.Type] = len(applied)
+		applied = append(applied, m)
+	}
+	return applied
+}
+
+func newBreakdown(stat Statistic, base int, modifiers []Modifier) Breakdown {
+	b := Breakdown{Statistic: stat, Base: base, Modifiers: stackModifiers(modifiers), Total: base}
+	for _, m := range b.Modifiers {
+		b.Total += m.Value
+	}
+	return b
+}
+
+// Bonus is the proficiency bonus of the rank at a level: nothing when
+// untrained, otherwise the level plus 2, 4, 6 or 8
+func (p Proficiency) Bonus(level int) int {
+	if p == Untrained {
+		return 0
+	}
+	return level + 2*int(p)
+}
+
+// statisticAttributes is the attribute each statistic is based on. Class DC
+// uses the entity's key attribute instead.
+var statisticAttributes = map[Statistic]AttributeName{
+	StatAC:                  Dexterity,
+	StatPerception:          Wisdom,
+	StatAttack:              Strength,
+	Statistic(Fortitude):    Constitution,
+	Statistic(Reflex):       Dexterity,
+	Statistic(Will):         Wisdom,
+	Statistic(Acrobatics):   Dexterity,
+	Statistic(Arcana):       Intelligence,
+	Statistic(Athletics):    Strength,
+	Statistic(Crafting):     Intelligence,
+	Statistic(Deception):    Charisma,
+	Statistic(Diplomacy):    Charisma,
+	Statistic(Intimidation): Charisma,
+	Statistic(Medicine):     Wisdom,
+	Statistic(Nature):       Wisdom,
+	Statistic(Occultism):    Intelligence,
+	Statistic(Performance):  Charisma,
+	Statistic(Religion):     Wisdom,
+	Statistic(Society):      Intelligence,
+	Statistic(Stealth):      Dexterity,
+	Statistic(Survival):     Wisdom,
+	Statistic(Thievery):     Dexterity,
+}
+
+// NewCharacter creates an entity whose statistics are derived from its level,
+// attribute modifiers and proficiencies rather than set directly. Characters
+// fall unconscious and start dying at 0 HP rather than dying outright.
+func NewCharacter(name string, level, hp int, attributes *Attributes, faction Faction) *Entity {
+	e := NewEntity(name, hp, 0, faction)
+	e.Level = level
+	e.DiesAtZeroHP = false
+	e.Attributes = attributes
+	e.Proficiencies = make(map[Statistic]Proficiency)
+	e.ItemBonuses = make(map[Statistic]int)
+	return e
+}
+
+// SetProficiency sets the entity's proficiency rank in a statistic
+func (e *Entity) SetProficiency(stat Statistic, rank Proficiency) {
+	if e.Proficiencies == nil {
+		e.Proficiencies = make(map[Statistic]Proficiency)
+	}
+	e.Proficiencies[stat] = rank
+}
+
+// SetItemBonus sets the item bonus the entity's gear gives a statistic
+func (e *Entity) SetItemBonus(stat Statistic, bonus int) {
+	if e.ItemBonuses == nil {
+		e.ItemBonuses = make(map[Statistic]int)
+	}
+	e.ItemBonuses[stat] = bonus
+}
+
+// Breakdown works out a statistic from its parts. Entities without attributes
+// report the value they were given directly as a single modifier.
+func (e *Entity) Breakdown(stat Statistic) Breakdown {
+	return e.breakdown(stat, false)
+}
+
+// breakdown works out a statistic, leaving out the armor check penalty for
+// checks with the attack trait
+func (e *Entity) breakdown(stat Statistic, attackTrait bool) Breakdown {
+	base := 0
+	if stat.isDC() {
+		base = 10
+	}
+	modifiers := append(e.statisticModifiers(stat), e.armorModifiers(stat, attackTrait)...)
+	modifiers = append(modifiers, e.shieldModifiers(stat)...)
+	return newBreakdown(stat, base, append(modifiers, e.conditionModifiers(stat, e.statisticAttribute(stat))...))
+}
+
+// AttackBreakdown works out the attack bonus of a Strike with the attack. For
+// entities with attributes the attack's own bonus counts as an item bonus.
+func (e *Entity) AttackBreakdown(attack BaseAttack) Breakdown {
+	var modifiers []Modifier
+	if e.Attributes == nil {
+		modifiers = []Modifier{{Source: "weapon", Type: UntypedModifier, Value: attack.Bonus}}
+	} else {
+		modifiers = append(e.statisticModifiers(StatAttack), Modifier{Source: "weapon", Type: ItemModifier, Value: attack.Bonus})
+	}
+	return newBreakdown(StatAttack, 0, append(modifiers, e.conditionModifiers(StatAttack, Strength)...))
+}
+
+// ArmorClass returns the entity's AC
+func (e *Entity) ArmorClass() int {
+	return e.DC(StatAC)
+}
+
+// statisticAttribute is the attribute a statistic is based on, if any
+func (e *Entity) statisticAttribute(stat Statistic) AttributeName {
+	switch stat {
+	case StatClassDC:
+		return e.KeyAttribute
+	case StatSpellAttack, StatSpellDC:
+		if e.Spellcasting != nil {
+			return e.Spellcasting.KeyAttribute
+		}
+		return e.KeyAttribute
+	}
+	return statisticAttributes[stat]
+}
+
+func (e *Entity) statisticModifiers(stat Statistic) []Modifier {
+	if e.Attributes == nil {
+		return []Modifier{{Source: statisticName(stat), Type: UntypedModifier, Value: e.legacyModifier(stat)}}
+	}
+	return e.derivedModifiers(stat, e.statisticAttribute(stat))
+}
+
+// derivedModifiers are the attribute, proficiency and item modifiers of a
+// statistic of an entity with attributes. attribute may be empty.
+func (e *Entity) derivedModifiers(stat Statistic, attribute AttributeName) []Modifier {
+	var modifiers []Modifier
+	if attribute != "" {
+		value := e.Attributes.Modifier(attribute)
+		if stat == StatAC && e.Armor != nil {
+			value = min(value, e.Armor.DexCap)
+		}
+		modifiers = append(modifiers, Modifier{Source: attributeName(attribute), Type: AttributeModifier, Value: value})
+	}
+	rank := e.proficiency(stat)
+	modifiers = append(modifiers, Modifier{Source: rank.String(), Type: ProficiencyModifier, Value: rank.Bonus(e.Level)})
+	if bonus := e.ItemBonuses[stat]; bonus != 0 {
+		modifiers = append(modifiers, Modifier{Source: "item", Type: ItemModifier, Value: bonus})
+	}
+	return modifiers
+}
+
+// proficiency is the entity's rank in a statistic. The spell DC shares the
+// spell attack's rank, so setting StatSpellAttack covers both.
+func (e *Entity) proficiency(stat Statistic) Proficiency {
+	if stat == StatSpellDC {
+		stat = StatSpellAttack
+	}
+	return e.Proficiencies[stat]
+}
+
+// legacyModifier is the modifier set directly on an entity without attributes
+func (e *Entity) legacyModifier(stat Statistic) int {
+	switch stat {
+	case StatAC:
+		return e.AC - 10
+	case StatPerception:
+		return e.Perception
+	case StatAttack, StatClassDC:
+		return 0
+	case StatSpellAttack, StatSpellDC:
+		if e.Spellcasting == nil {
+			return 0
+		}
+		if stat == StatSpellDC {
+			return e.Spellcasting.DC - 10
+		}
+		return e.Spellcasting.Attack
+	case Statistic(Fortitude), Statistic(Reflex), Statistic(Will):
+		return e.Saves[SaveType(stat)]
+	default:
+		return e.Skills[Skill(stat)]
+	}
+}
+
+// attributeName turns "STRENGTH" into "Strength" for logs
+func attributeName(name AttributeName) string {
+	return statisticName(Statistic(name))
+}
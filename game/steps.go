@@ -13,6 +13,20 @@ const (
 	AfterAttack  StepType = "AFTER_ATTACK"
 	StartTurn    StepType = "START_TURN"
 	EndTurn      StepType = "END_TURN"
+	BeforeSave   StepType = "BEFORE_SAVE"
+	AfterSave    StepType = "AFTER_SAVE"
+	BeforeCheck  StepType = "BEFORE_CHECK"
+	AfterCheck   StepType = "AFTER_CHECK"
+	BeforeHeal   StepType = "BEFORE_HEAL"
+	AfterHeal    StepType = "AFTER_HEAL"
+	// PersistentDamageTick is taken when persistent damage is dealt at the end of a turn
+	PersistentDamageTick StepType = "PERSISTENT_DAMAGE"
+	StartAction          StepType = "START_ACTION"
+	EndAction            StepType = "END_ACTION"
+	// EntityMove is taken each time a moving entity is about to leave a square
+	EntityMove StepType = "MOVE"
+	// AreaEffect is taken when an area effect is placed on the grid
+	AreaEffect StepType = "AREA"
 )
 
 type Step interface {
@@ -33,10 +47,12 @@ func (s BaseStep) Metadata() map[string]interface{} {
 	return s.metadata
 }
 
+// Trigger reacts to steps of the type it is registered for. Triggers are
+// registered per game so that concurrent games never share state.
 type Trigger interface {
 	Priority() int
-	Condition(Step) bool
-	Execute(Step)
+	Condition(gs *GameState, step Step) bool
+	Execute(gs *GameState, step Step)
 }
 
 type BaseTrigger struct {
@@ -47,13 +63,17 @@ func (t BaseTrigger) Priority() int {
 	return t.priority
 }
 
-var triggers map[StepType][]Trigger
-
-func RegisterTrigger(trigger Trigger, t StepType) {
-	if triggers == nil {
-		triggers = make(map[StepType][]Trigger)
+// RegisterTrigger registers a trigger for steps of type t in this game.
+// Triggers with a higher priority run first.
+func (gs *GameState) RegisterTrigger(trigger Trigger, t StepType) {
+	if gs.Triggers == nil {
+		gs.Triggers = make(map[StepType][]Trigger)
 	}
-	triggers[t] = append(triggers[t], trigger)
+	triggersForStep := append(gs.Triggers[t], trigger)
+	sort.SliceStable(triggersForStep, func(i, j int) bool {
+		return triggersForStep[i].Priority() > triggersForStep[j].Priority()
+	})
+	gs.Triggers[t] = triggersForStep
 }
 
 func executeStep(gs *GameState, step Step, logMessage string) {
@@ -65,15 +85,5 @@ func executeStep(gs *GameState, step Step, logMessage string) {
 		gs.StepCallback(step, logMessage)
 	}
 
-	if triggersForStep, ok := triggers[step.Type()]; ok {
-		sort.Slice(triggersForStep, func(i, j int) bool {
-			return triggersForStep[i].Priority() > triggersForStep[j].Priority()
-		})
-
-		for _, trigger := range triggersForStep {
-			if trigger.Condition(step) {
-				trigger.Execute(step)
-			}
-		}
-	}
+	gs.runTriggers(step, logMessage)
 }
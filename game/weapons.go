@@ -0,0 +1,480 @@
+package game
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	dice "pf2eEngine/util"
+	"strings"
+)
+
+// WeaponGroup is the family a weapon belongs to, used by critical specialization effects and feats
+type WeaponGroup string
+
+const (
+	AxeGroup      WeaponGroup = "AXE"
+	BowGroup      WeaponGroup = "BOW"
+	BrawlingGroup WeaponGroup = "BRAWLING"
+	ClubGroup     WeaponGroup = "CLUB"
+	FlailGroup    WeaponGroup = "FLAIL"
+	HammerGroup   WeaponGroup = "HAMMER"
+	KnifeGroup    WeaponGroup = "KNIFE"
+	PickGroup     WeaponGroup = "PICK"
+	PolearmGroup  WeaponGroup = "POLEARM"
+	SlingGroup    WeaponGroup = "SLING"
+	SpearGroup    WeaponGroup = "SPEAR"
+	SwordGroup    WeaponGroup = "SWORD"
+)
+
+type WeaponTraitName string
+
+const (
+	Agile       WeaponTraitName = "AGILE"       // Multiple attack penalty is -4 and -8
+	Finesse     WeaponTraitName = "FINESSE"     // Attacks can use Dexterity instead of Strength
+	Deadly      WeaponTraitName = "DEADLY"      // Critical hits add an extra die of the trait's size
+	Fatal       WeaponTraitName = "FATAL"       // Critical hits use the trait's die and add one more
+	Forceful    WeaponTraitName = "FORCEFUL"    // Later attacks in a turn deal more damage per die
+	Sweep       WeaponTraitName = "SWEEP"       // +1 to attack a different target than earlier this turn
+	Backstabber WeaponTraitName = "BACKSTABBER" // +1 precision damage against off-guard targets
+	Versatile   WeaponTraitName = "VERSATILE"   // Can deal the trait's damage type instead
+	TwoHand     WeaponTraitName = "TWO_HAND"    // Uses the trait's die when wielded in two hands
+	Thrown      WeaponTraitName = "THROWN"      // Can be thrown; melee weapons give the range increment in Feet
+	Volley      WeaponTraitName = "VOLLEY"      // -2 to attack targets within the trait's Feet
+)
+
+// WeaponTrait is a trait of a weapon. Some traits carry a die size, e.g. deadly
+// d10, a damage type, e.g. versatile P, or a distance, e.g. volley 30 feet.
+type WeaponTrait struct {
+	Name WeaponTraitName
+	Die  int
+	Type DamageType
+	Feet int
+}
+
+// String renders the trait for logs, e.g. "deadly d10"
+func (t WeaponTrait) String() string {
+	name := displayName(string(t.Name))
+	switch {
+	case t.Die > 0:
+		return fmt.Sprintf("%s d%d", name, t.Die)
+	case t.Type != "":
+		return fmt.Sprintf("%s %s", name, displayName(string(t.Type)))
+	case t.Feet > 0:
+		return fmt.Sprintf("%s %d ft.", name, t.Feet)
+	default:
+		return name
+	}
+}
+
+// Weapon is a weapon an entity can wield and Strike with
+type Weapon struct {
+	Name        string
+	DamageDice  int // Number of weapon damage dice; zero counts as one
+	DamageDie   int
+	DamageType  DamageType
+	DamageBonus int // Flat damage added to every hit, e.g. a monster's Strength
+	Bonus       int // Item bonus to attack rolls; the whole attack bonus for entities without attributes
+	Group       WeaponGroup
+	Hands       int    // Hands needed to wield the weapon
+	Range       int    // Range increment in feet; zero for melee weapons
+	Reach       int    // Reach in feet; zero counts as 5
+	Ammunition  string // Kind of ammunition fired, e.g. "Arrows"; empty for none
+	Reload      int    // Actions needed to load the weapon after each shot
+	Traits      []WeaponTrait
+}
+
+// Trait returns the weapon's trait with the given name, if it has it
+func (w Weapon) Trait(name WeaponTraitName) (WeaponTrait, bool) {
+	for _, t := range w.Traits {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return WeaponTrait{}, false
+}
+
+// HasTrait reports whether the weapon has the trait
+func (w Weapon) HasTrait(name WeaponTraitName) bool {
+	_, ok := w.Trait(name)
+	return ok
+}
+
+// IsRanged reports whether the weapon is a ranged weapon
+func (w Weapon) IsRanged() bool {
+	return w.Range > 0
+}
+
+// ReachFeet is how far away the weapon can hit in melee
+func (w Weapon) ReachFeet() int {
+	if w.Reach == 0 {
+		return 5
+	}
+	return w.Reach
+}
+
+func (w Weapon) dice() int {
+	return max(1, w.DamageDice)
+}
+
+// String renders the weapon for logs, e.g. "Longsword (1d8 slashing; versatile piercing)"
+func (w Weapon) String() string {
+	s := fmt.Sprintf("%s (%dd%d %s", w.Name, w.dice(), w.DamageDie, strings.ToLower(string(w.DamageType)))
+	if len(w.Traits) > 0 {
+		traits := make([]string, len(w.Traits))
+		for i, t := range w.Traits {
+			traits[i] = t.String()
+		}
+		s += "; " + strings.Join(traits, ", ")
+	}
+	return s + ")"
+}
+
+// damageTypes lists the damage types the weapon can deal: its own and any versatile type
+func (w Weapon) damageTypes() []DamageType {
+	types := []DamageType{w.DamageType}
+	for _, t := range w.Traits {
+		if t.Name == Versatile {
+			types = append(types, t.Type)
+		}
+	}
+	return types
+}
+
+// Common weapons
+var (
+	Dagger = Weapon{Name: "Dagger", DamageDie: 4, DamageType: Piercing, Group: KnifeGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: Agile}, {Name: Finesse}, {Name: Thrown, Feet: 10}, {Name: Versatile, Type: Slashing}}}
+	Shortsword = Weapon{Name: "Shortsword", DamageDie: 6, DamageType: Piercing, Group: SwordGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: Agile}, {Name: Finesse}, {Name: Versatile, Type: Slashing}}}
+	Rapier = Weapon{Name: "Rapier", DamageDie: 6, DamageType: Piercing, Group: SwordGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: Deadly, Die: 8}, {Name: Finesse}}}
+	Longsword = Weapon{Name: "Longsword", DamageDie: 8, DamageType: Slashing, Group: SwordGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: Versatile, Type: Piercing}}}
+	BastardSword = Weapon{Name: "Bastard Sword", DamageDie: 8, DamageType: Slashing, Group: SwordGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: TwoHand, Die: 12}}}
+	Greatsword = Weapon{Name: "Greatsword", DamageDie: 12, DamageType: Slashing, Group: SwordGroup, Hands: 2,
+		Traits: []WeaponTrait{{Name: Versatile, Type: Piercing}}}
+	BattleAxe = Weapon{Name: "Battle Axe", DamageDie: 8, DamageType: Slashing, Group: AxeGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: Sweep}}}
+	Greataxe = Weapon{Name: "Greataxe", DamageDie: 12, DamageType: Slashing, Group: AxeGroup, Hands: 2,
+		Traits: []WeaponTrait{{Name: Sweep}}}
+	Pick = Weapon{Name: "Pick", DamageDie: 6, DamageType: Piercing, Group: PickGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: Fatal, Die: 10}}}
+	Scimitar = Weapon{Name: "Scimitar", DamageDie: 6, DamageType: Slashing, Group: SwordGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: Forceful}, {Name: Sweep}}}
+	Dogslicer = Weapon{Name: "Dogslicer", DamageDie: 6, DamageType: Slashing, Group: SwordGroup, Hands: 1,
+		Traits: []WeaponTrait{{Name: Agile}, {Name: Backstabber}, {Name: Finesse}}}
+	Warhammer = Weapon{Name: "Warhammer", DamageDie: 8, DamageType: Bludgeoning, Group: HammerGroup, Hands: 1}
+)
+
+// WieldedWeapon is a weapon held in one or more of an entity's hands
+type WieldedWeapon struct {
+	Weapon Weapon
+	Hands  int
+	Loaded bool // Whether a weapon with a reload value is ready to fire
+}
+
+// handsAvailable is the number of hands an entity has to wield weapons with
+const handsAvailable = 2
+
+// Wield takes up a weapon in the given number of hands and replaces the
+// entity's Strikes to match what it is now holding
+func (e *Entity) Wield(w Weapon, hands int) error {
+	if hands < max(1, w.Hands) {
+		return fmt.Errorf("%s needs %d hands", w.Name, w.Hands)
+	}
+	if free := e.freeHands(); hands > free {
+		return fmt.Errorf("%s has only %d free hands", e.Name, free)
+	}
+	e.Weapons = append(e.Weapons, WieldedWeapon{Weapon: w, Hands: hands})
+	e.refreshStrikes()
+	return nil
+}
+
+// freeHands is the number of hands the entity isn't using for a weapon or shield
+func (e *Entity) freeHands() int {
+	free := handsAvailable
+	for _, held := range e.Weapons {
+		free -= held.Hands
+	}
+	if e.Shield != nil {
+		free--
+	}
+	return free
+}
+
+// Unwield lets go of the named weapon and removes its Strike
+func (e *Entity) Unwield(name string) error {
+	for i, held := range e.Weapons {
+		if held.Weapon.Name == name {
+			e.Weapons = append(e.Weapons[:i:i], e.Weapons[i+1:]...)
+			e.refreshStrikes()
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not wielding %s", e.Name, name)
+}
+
+// refreshStrikes replaces the entity's Strike cards with one per wielded
+// weapon, and a Reload card for each that needs reloading, keeping them where
+// the old cards were among its action cards
+func (e *Entity) refreshStrikes() {
+	var cards []*ActionCard
+	at := -1
+	for _, card := range e.ActionCards {
+		if card.weapon != nil {
+			if at < 0 {
+				at = len(cards)
+			}
+			continue
+		}
+		cards = append(cards, card)
+	}
+	if at < 0 {
+		at = len(cards)
+	}
+
+	var strikes []*ActionCard
+	for _, held := range e.Weapons {
+		strikes = append(strikes, NewStrikeCard(held))
+		if held.Weapon.Reload > 0 {
+			strikes = append(strikes, NewReloadCard(held.Weapon))
+		}
+	}
+	e.ActionCards = append(cards[:at:at], append(strikes, cards[at:]...)...)
+}
+
+// DamageTypeParam chooses the damage type of a Strike with a versatile weapon
+const DamageTypeParam = "damageType"
+
+// NewStrikeCard creates a Strike with a wielded weapon against a target within
+// reach or, for ranged and thrown weapons, within range. A "damageType" param
+// picks the versatile damage type.
+func NewStrikeCard(held WieldedWeapon) *ActionCard {
+	w := held.Weapon
+	description := fmt.Sprintf("Make a Strike with your %s against a target within reach.", strings.ToLower(w.Name))
+	if increment := w.rangeIncrement(); increment > 0 {
+		description = fmt.Sprintf("Make a Strike with your %s against a target within range (increment %d feet).", strings.ToLower(w.Name), increment)
+	}
+	card := &ActionCard{
+		ID:          uuid.New(),
+		Name:        fmt.Sprintf("Strike (%s)", w.Name),
+		Type:        OneActionCard,
+		Description: description,
+		Traits:      []ActionTrait{AttackTrait},
+		weapon:      &held,
+		actionGenerator: func(gs *GameState, actor *Entity, params map[string]interface{}) (Action, error) {
+			target, err := getSingleTarget(gs, actor, []TargetCriterion{IsAlive(), Range(w.maxDistance()), InLineOfEffect()}, params)
+			if err != nil {
+				return Action{}, err
+			}
+			damageType, err := strikeDamageType(w, params)
+			if err != nil {
+				return Action{}, err
+			}
+			current := actor.wielded(w.Name)
+			if current == nil {
+				return Action{}, fmt.Errorf("actor is not wielding %s", w.Name)
+			}
+			if _, err := planStrike(gs, current, actor, target); err != nil {
+				return Action{}, err
+			}
+			return Action{
+				Name: "Strike",
+				Cost: 1,
+				perform: func(gs *GameState, actor *Entity) {
+					if current := actor.wielded(w.Name); current != nil {
+						PerformStrike(gs, current, damageType, actor, target)
+					}
+				},
+			}, nil
+		},
+	}
+	return card
+}
+
+// strikeDamageType reads the damage type chosen for a Strike, defaulting to the weapon's own
+func strikeDamageType(w Weapon, params map[string]interface{}) (DamageType, error) {
+	name, ok := params[DamageTypeParam].(string)
+	if !ok || name == "" {
+		return w.DamageType, nil
+	}
+	chosen, err := ParseDamageType(name)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range w.damageTypes() {
+		if t == chosen {
+			return chosen, nil
+		}
+	}
+	return "", fmt.Errorf("%s cannot deal %s damage", w.Name, strings.ToLower(string(chosen)))
+}
+
+// StrikeBreakdown works out the attack bonus of a Strike with a weapon against
+// a target. Finesse weapons use the better of Strength and Dexterity and ranged
+// Strikes, including thrown weapons, use Dexterity. For entities with
+// attributes the weapon's bonus counts as an item bonus.
+func (e *Entity) StrikeBreakdown(held WieldedWeapon, target *Entity, ranged bool) Breakdown {
+	w := held.Weapon
+	attribute := e.strikeAttribute(w, ranged)
+	var modifiers []Modifier
+	if e.Attributes == nil {
+		modifiers = []Modifier{{Source: "weapon", Type: UntypedModifier, Value: w.Bonus}}
+	} else {
+		modifiers = append(e.derivedModifiers(StatAttack, attribute), Modifier{Source: "weapon", Type: ItemModifier, Value: w.Bonus})
+	}
+	if w.HasTrait(Sweep) && e.attackedOtherThan(target) {
+		modifiers = append(modifiers, Modifier{Source: "sweep", Type: CircumstanceModifier, Value: 1})
+	}
+	return newBreakdown(StatAttack, 0, append(modifiers, e.conditionModifiers(StatAttack, attribute)...))
+}
+
+// strikeAttribute is the attribute the entity attacks with using the weapon
+func (e *Entity) strikeAttribute(w Weapon, ranged bool) AttributeName {
+	switch {
+	case ranged:
+		return Dexterity
+	case w.HasTrait(Finesse) && e.Attributes != nil && e.Attributes.Dexterity > e.Attributes.Strength:
+		return Dexterity
+	default:
+		return Strength
+	}
+}
+
+// weaponMAP is the multiple attack penalty for a Strike with the weapon
+func weaponMAP(w Weapon, attacksMade int) int {
+	if w.HasTrait(Agile) {
+		return -4 * min(attacksMade, 2)
+	}
+	return multipleAttackPenalty(attacksMade)
+}
+
+// turnAttack is an attack an entity made on its turn
+type turnAttack struct {
+	target *Entity
+	weapon string // Name of the weapon used; empty for attacks without one
+}
+
+// recordAttack counts an attack made on the attacker's turn towards its
+// multiple attack penalty and remembers its target and weapon for the rest of
+// the turn. Attacks outside the attacker's own turn, such as Reactive Strikes,
+// take no penalty and don't add to it.
+func (gs *GameState) recordAttack(attacker *Entity, target *Entity, weapon *Weapon) {
+	if !gs.IsEntityTurn(attacker) {
+		return
+	}
+	attacker.MapCounter++
+	attacker.attacksThisTurn = append(attacker.attacksThisTurn, turnAttack{target: target, weapon: weaponName(weapon)})
+}
+
+// attackedOtherThan reports whether the entity attacked a different target earlier this turn
+func (e *Entity) attackedOtherThan(target *Entity) bool {
+	for _, a := range e.attacksThisTurn {
+		if a.target != target {
+			return true
+		}
+	}
+	return false
+}
+
+// attacksWith counts the entity's earlier attacks this turn with the named weapon
+func (e *Entity) attacksWith(weapon string) int {
+	count := 0
+	for _, a := range e.attacksThisTurn {
+		if a.weapon == weapon {
+			count++
+		}
+	}
+	return count
+}
+
+// PerformStrike makes a Strike with a wielded weapon, dealing damageType damage
+// on a hit. The defender must be within the weapon's reach or range; ranged
+// Strikes take range penalties and use up ammunition. It returns the attack,
+// or nil if the Strike couldn't be made.
+func PerformStrike(gs *GameState, held *WieldedWeapon, damageType DamageType, attacker *Entity, defender *Entity) *Attack {
+	plan, err := planStrike(gs, held, attacker, defender)
+	if err != nil {
+		gs.Printf("%s cannot Strike %s: %v.\n", attacker.Name, defender.Name, err)
+		return nil
+	}
+	// The Strike uses a copy, as throwing the weapon lets go of it
+	wielded := *held
+	w := wielded.Weapon
+	modifiers := append(attacker.StrikeBreakdown(wielded, defender, plan.ranged).DiceModifiers(),
+		dice.Modifier{Source: "MAP", Value: weaponMAP(w, attacker.MapCounter)})
+	modifiers = append(modifiers, plan.rangeModifiers(w)...)
+	plan.fire(gs, held, attacker)
+	return resolveAttack(gs, attacker, defender, &w, modifiers, func(critical bool) Damage {
+		return rollStrikeDamage(gs, wielded, plan, damageType, attacker, defender, critical)
+	})
+}
+
+// rollStrikeDamage rolls a Strike's damage. Melee and thrown weapons add
+// Strength for entities with attributes. A critical hit doubles the damage
+// before extra dice from deadly and fatal are added.
+func rollStrikeDamage(gs *GameState, held WieldedWeapon, plan strikePlan, damageType DamageType, attacker *Entity, defender *Entity, critical bool) Damage {
+	w := held.Weapon
+	die := w.DamageDie
+	if twoHand, ok := w.Trait(TwoHand); ok && held.Hands >= 2 {
+		die = twoHand.Die
+	}
+	fatal, isFatal := w.Trait(Fatal)
+	if critical && isFatal {
+		die = fatal.Die
+	}
+
+	bonus := w.DamageBonus
+	if attacker.Attributes != nil && (!plan.ranged || plan.thrown) {
+		bonus += attacker.Attributes.Strength
+	}
+	// Forceful only counts earlier attacks with the same weapon
+	if w.HasTrait(Forceful) {
+		bonus += min(attacker.attacksWith(w.Name), 2) * w.dice()
+	}
+
+	rolls := []DamageRoll{{Die: die, Count: w.dice(), Bonus: bonus, Type: damageType}}
+	if w.HasTrait(Backstabber) && defender.HasCondition(OffGuard) {
+		rolls = append(rolls, DamageRoll{Bonus: 1, Type: damageType, Precision: true})
+	}
+
+	damage := rollDamage(gs, attacker, defender, rolls)
+	if !critical {
+		return damage
+	}
+	damage = damage.Double()
+
+	// Extra critical dice aren't doubled
+	var extra []DamageRoll
+	if deadly, ok := w.Trait(Deadly); ok {
+		extra = append(extra, DamageRoll{Die: deadly.Die, Count: deadlyDice(w.dice()), Type: damageType})
+	}
+	if isFatal {
+		extra = append(extra, DamageRoll{Die: fatal.Die, Count: 1, Type: damageType})
+	}
+	defer gs.rollAs(attacker)()
+	for _, dr := range extra {
+		amount, record := dr.Roll(gs.Dice)
+		damage.Rolls = append(damage.Rolls, record)
+		amount.Amount += damage.Amount[amount.Type].Amount
+		amount.Precision += damage.Amount[amount.Type].Precision
+		damage.Amount[amount.Type] = amount
+	}
+	return damage
+}
+
+// deadlyDice is the number of deadly dice a weapon with weaponDice damage dice adds on a critical hit
+func deadlyDice(weaponDice int) int {
+	return max(1, weaponDice-1)
+}
+
+// getStrikeCards returns the entity's Strikes, one per wielded weapon
+func getStrikeCards(e *Entity) []*ActionCard {
+	var cards []*ActionCard
+	for _, card := range e.ActionCards {
+		if card.weapon != nil && strings.HasPrefix(card.Name, "Strike") {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
@@ -0,0 +1,200 @@
+package game
+
+import (
+	dice "pf2eEngine/util"
+	"testing"
+)
+
+// newStrikeTest puts a fighter wielding the weapons next to two dummies with
+// AC 15 and starts the fighter's turn. The fighter has no attributes, so it
+// attacks at +10 from the weapons' Bonus and deals just the weapon dice.
+func newStrikeTest(t *testing.T, weapons ...WieldedWeapon) (*GameState, *Entity, *Entity, *Entity) {
+	t.Helper()
+	fighter := NewEntity("Fighter", 30, 15, GoodGuys)
+	for _, held := range weapons {
+		w := held.Weapon
+		w.Bonus = 10
+		if err := fighter.Wield(w, held.Hands); err != nil {
+			t.Fatalf("Wield returned error: %v", err)
+		}
+	}
+	first := NewEntity("First", 100, 15, BadGuys)
+	second := NewEntity("Second", 100, 15, BadGuys)
+	gs := newTestGame([]Spawn{NewSpawn(fighter, 1, 1), NewSpawn(first, 2, 1), NewSpawn(second, 1, 2)}, dice.NewSeededSource(1))
+	for i, e := range gs.Initiative {
+		if e == fighter {
+			gs.CurrentTurn = i
+		}
+	}
+	return gs, fighter, first, second
+}
+
+// strike has the attacker Strike the target with the named weapon, rolling
+// exactly the scripted dice, and returns the attack and the damage it dealt
+func strike(t *testing.T, gs *GameState, attacker *Entity, weapon string, damageType DamageType, target *Entity, rolls ...dice.ScriptedRoll) (*Attack, int) {
+	t.Helper()
+	held := attacker.wielded(weapon)
+	if held == nil {
+		t.Fatalf("%s is not wielding %s", attacker.Name, weapon)
+	}
+	src := scriptDice(gs, rolls...)
+	hp := target.HP
+	attack := PerformStrike(gs, held, damageType, attacker, target)
+	if attack == nil {
+		t.Fatalf("%s could not Strike %s", attacker.Name, target.Name)
+	}
+	if src.Remaining() != 0 {
+		t.Fatalf("the Strike left %d scripted rolls unused", src.Remaining())
+	}
+	return attack, hp - target.HP
+}
+
+func d20(value int) dice.ScriptedRoll { return dice.ScriptedRoll{Sides: 20, Value: value} }
+
+func die(sides, value int) dice.ScriptedRoll { return dice.ScriptedRoll{Sides: sides, Value: value} }
+
+func TestCriticalTraits(t *testing.T) {
+	tests := []struct {
+		name   string
+		weapon Weapon
+		rolls  []dice.ScriptedRoll
+		want   int
+	}{
+		{"deadly adds nothing on a hit", Rapier, []dice.ScriptedRoll{d20(5), die(6, 3)}, 3},
+		{"deadly adds an undoubled die on a critical hit", Rapier, []dice.ScriptedRoll{d20(15), die(6, 3), die(8, 5)}, 11},
+		{"fatal keeps the weapon die on a hit", Pick, []dice.ScriptedRoll{d20(5), die(6, 3)}, 3},
+		{"fatal raises the die and adds one on a critical hit", Pick, []dice.ScriptedRoll{d20(15), die(10, 4), die(10, 7)}, 15},
+		{"plain critical hit doubles", Warhammer, []dice.ScriptedRoll{d20(15), die(8, 4)}, 8},
+	}
+	for _, tt := range tests {
+		gs, fighter, target, _ := newStrikeTest(t, WieldedWeapon{Weapon: tt.weapon, Hands: 1})
+		if _, dealt := strike(t, gs, fighter, tt.weapon.Name, tt.weapon.DamageType, target, tt.rolls...); dealt != tt.want {
+			t.Errorf("%s: dealt %d damage, want %d", tt.name, dealt, tt.want)
+		}
+	}
+}
+
+func TestForceful(t *testing.T) {
+	gs, fighter, target, _ := newStrikeTest(t, WieldedWeapon{Weapon: Scimitar, Hands: 1}, WieldedWeapon{Weapon: Dagger, Hands: 1})
+
+	// Attacking with the dagger first raises the multiple attack penalty but
+	// doesn't make the scimitar's first attack forceful
+	strike(t, gs, fighter, "Dagger", Piercing, target, d20(5), die(4, 1))
+	steps := []struct {
+		d20, bonus, damage int
+	}{
+		{10, 5, 3}, // -5 after the dagger's attack, but no forceful bonus
+		{15, 0, 4}, // -10, +1 per die
+		{15, 0, 5}, // -10, +2 per die
+		{15, 0, 5}, // forceful stops at +2 per die
+	}
+	for i, s := range steps {
+		attack, dealt := strike(t, gs, fighter, "Scimitar", Slashing, target, d20(s.d20), die(6, 3))
+		if attack.Bonus != s.bonus {
+			t.Errorf("scimitar attack %d: bonus %+d, want %+d", i+1, attack.Bonus, s.bonus)
+		}
+		if dealt != s.damage {
+			t.Errorf("scimitar attack %d: dealt %d damage, want %d", i+1, dealt, s.damage)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	gs, fighter, first, second := newStrikeTest(t, WieldedWeapon{Weapon: BattleAxe, Hands: 1})
+	attacks := []struct {
+		target *Entity
+		bonus  int
+	}{
+		{first, 10},
+		{first, 5},  // Same target: no sweep bonus
+		{second, 1}, // -10 for the third attack, +1 for a different target
+	}
+	for i, a := range attacks {
+		attack, _ := strike(t, gs, fighter, "Battle Axe", Slashing, a.target, d20(15), die(8, 1))
+		if attack.Bonus != a.bonus {
+			t.Errorf("attack %d against %s: bonus %+d, want %+d", i+1, a.target.Name, attack.Bonus, a.bonus)
+		}
+	}
+}
+
+func TestBackstabber(t *testing.T) {
+	tests := []struct {
+		name     string
+		offGuard bool
+		want     int
+	}{
+		{"target not off-guard", false, 3},
+		{"off-guard target takes 1 precision damage", true, 4},
+	}
+	for _, tt := range tests {
+		gs, fighter, target, _ := newStrikeTest(t, WieldedWeapon{Weapon: Dogslicer, Hands: 1})
+		d := 5
+		if tt.offGuard {
+			target.AddCondition(Condition{Name: OffGuard})
+			d = 3 // Off-guard lowers the target's AC to 13
+		}
+		if _, dealt := strike(t, gs, fighter, "Dogslicer", Slashing, target, d20(d), die(6, 3)); dealt != tt.want {
+			t.Errorf("%s: dealt %d damage, want %d", tt.name, dealt, tt.want)
+		}
+	}
+}
+
+func TestVersatile(t *testing.T) {
+	tests := []struct {
+		damageType DamageType
+		want       int
+	}{
+		{Slashing, 4},
+		{Piercing, 9}, // The target's weakness to piercing applies
+	}
+	for _, tt := range tests {
+		gs, fighter, target, _ := newStrikeTest(t, WieldedWeapon{Weapon: Longsword, Hands: 1})
+		target.SetWeakness(Piercing, 5)
+		if _, dealt := strike(t, gs, fighter, "Longsword", tt.damageType, target, d20(5), die(8, 4)); dealt != tt.want {
+			t.Errorf("%s Strike dealt %d damage, want %d", tt.damageType, dealt, tt.want)
+		}
+	}
+
+	for _, name := range []string{"", "piercing", "SLASHING"} {
+		if _, err := strikeDamageType(Longsword, map[string]interface{}{DamageTypeParam: name}); err != nil {
+			t.Errorf("strikeDamageType(%q) returned error: %v", name, err)
+		}
+	}
+	if _, err := strikeDamageType(Longsword, map[string]interface{}{DamageTypeParam: "bludgeoning"}); err == nil {
+		t.Errorf("a longsword Strike dealt bludgeoning damage")
+	}
+}
+
+func TestAgileMAP(t *testing.T) {
+	tests := []struct {
+		weapon Weapon
+		want   []int
+	}{
+		{Longsword, []int{0, -5, -10, -10}},
+		{Dagger, []int{0, -4, -8, -8}},
+	}
+	for _, tt := range tests {
+		for attacks, want := range tt.want {
+			if got := weaponMAP(tt.weapon, attacks); got != want {
+				t.Errorf("%s after %d attacks: MAP %d, want %d", tt.weapon.Name, attacks, got, want)
+			}
+		}
+	}
+}
+
+func TestTwoHand(t *testing.T) {
+	tests := []struct {
+		name  string
+		hands int
+		roll  dice.ScriptedRoll
+	}{
+		{"one hand rolls the weapon die", 1, die(8, 8)},
+		{"two hands roll the two-hand die", 2, die(12, 8)},
+	}
+	for _, tt := range tests {
+		gs, fighter, target, _ := newStrikeTest(t, WieldedWeapon{Weapon: BastardSword, Hands: tt.hands})
+		if _, dealt := strike(t, gs, fighter, "Bastard Sword", Slashing, target, d20(5), tt.roll); dealt != 8 {
+			t.Errorf("%s: dealt %d damage, want 8", tt.name, dealt)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package items
+
+import (
+	"pf2eEngine/game"
+)
+
+// ReactiveStrike has its owner Strike an enemy within reach that leaves a
+// square during a move action or uses a manipulate action, using their
+// reaction. Steps and teleports don't trigger it. A critical hit disrupts the
+// manipulate action. Register it for both
+// game.EntityMove and game.StartAction steps.
+type ReactiveStrike struct {
+	Owner *game.Entity
+}
+
+// Enforce ReactiveStrike implements the Trigger interface
+var _ game.Trigger = ReactiveStrike{}
+
+// Enforce ReactiveStrike is offered to its owner as a reaction
+var _ game.ReactionTrigger = ReactiveStrike{}
+
+// Enforce ReactiveStrike follows its owner into copies of the game
+var _ game.CopyableReaction = ReactiveStrike{}
+
+func (trigger ReactiveStrike) Reactor() *game.Entity {
+	return trigger.Owner
+}
+
+// WithReactor gives the same reaction to e, for a copy of the game
+func (trigger ReactiveStrike) WithReactor(e *game.Entity) game.ReactionTrigger {
+	return ReactiveStrike{Owner: e}
+}
+
+func (trigger ReactiveStrike) ReactionName() string {
+	return "Reactive Strike"
+}
+
+func (trigger ReactiveStrike) Priority() int {
+	return 10
+}
+
+func (trigger ReactiveStrike) Condition(gs *game.GameState, step game.Step) bool {
+	switch s := step.(type) {
+	case game.MoveStep:
+		return !s.NoReactions && trigger.Owner.CanReactiveStrike(gs, s.Entity)
+	case game.StartActionStep:
+		return s.Action.HasTrait(game.ManipulateTrait) && trigger.Owner.CanReactiveStrike(gs, s.Actor)
+	}
+	return false
+}
+
+func (trigger ReactiveStrike) Execute(gs *game.GameState, step game.Step) {
+	switch s := step.(type) {
+	case game.MoveStep:
+		trigger.strike(gs, s.Entity)
+	case game.StartActionStep:
+		attack := trigger.strike(gs, s.Actor)
+		if attack != nil && attack.Degree == game.CriticalSuccess {
+			s.Action.Disrupted = true
+		}
+	}
+}
+
+// strike makes the Reactive Strike, returning the attack if one was made
+func (trigger ReactiveStrike) strike(gs *game.GameState, target *game.Entity) *game.Attack {
+	attack, err := game.ReactiveStrike(gs, trigger.Owner, target)
+	if err != nil {
+		gs.Printf("%s cannot make a Reactive Strike: %v\n", trigger.Owner.Name, err)
+	}
+	return attack
+}
@@ -0,0 +1,132 @@
+package items
+
+import (
+	"io"
+	"pf2eEngine/game"
+	dice "pf2eEngine/util"
+	"strings"
+	"testing"
+)
+
+// newReactiveStrikeTest puts a guard with Reactive Strike and a longsword at
+// +10 next to a goblin with a crossbow, stride, step and reload. Neither has a
+// controller, so the guard always takes its reaction. The goblin has AC 15.
+func newReactiveStrikeTest(t *testing.T, goblinHP int) (*game.GameState, *game.Entity, *game.Entity) {
+	t.Helper()
+	guard := game.NewEntity("Guard", 30, 15, game.GoodGuys)
+	longsword := game.Longsword
+	longsword.Bonus = 10
+	if err := guard.Wield(longsword, 1); err != nil {
+		t.Fatalf("Wield returned error: %v", err)
+	}
+	goblin := game.NewEntity("Goblin", goblinHP, 15, game.BadGuys)
+	if err := goblin.Wield(game.Crossbow, 2); err != nil {
+		t.Fatalf("Wield returned error: %v", err)
+	}
+	goblin.Ammunition = map[string]int{"Bolts": 5}
+	goblin.AddActionCard(game.NewStrideCard())
+	goblin.AddActionCard(game.NewStepCard())
+
+	gs := game.NewGameStateWithSource([]game.Spawn{
+		{Unit: guard, Coordinates: [2]int{1, 1}},
+		{Unit: goblin, Coordinates: [2]int{2, 1}},
+	}, 10, 10, dice.NewSeededSource(1))
+	gs.Out = io.Discard
+	for _, e := range gs.Initiative {
+		e.Controller = nil
+	}
+	gs.RegisterTrigger(ReactiveStrike{Owner: guard}, game.EntityMove)
+	gs.RegisterTrigger(ReactiveStrike{Owner: guard}, game.StartAction)
+	return gs, guard, goblin
+}
+
+// act has the goblin play the card whose name starts with prefix, rolling
+// exactly the scripted dice
+func act(t *testing.T, gs *game.GameState, goblin *game.Entity, prefix string, params map[string]interface{}, rolls ...dice.ScriptedRoll) {
+	t.Helper()
+	src := dice.NewScriptedSource(nil, rolls...)
+	gs.Dice = src
+	for _, card := range goblin.ActionCards {
+		if !strings.HasPrefix(card.Name, prefix) {
+			continue
+		}
+		action, err := card.GenerateAction(gs, goblin, params)
+		if err != nil {
+			t.Fatalf("goblin cannot play %s: %v", card.Name, err)
+		}
+		game.ExecuteAction(gs, goblin, action)
+		if src.Remaining() != 0 {
+			t.Fatalf("%s left %d scripted rolls unused", card.Name, src.Remaining())
+		}
+		return
+	}
+	t.Fatalf("goblin has no %s card", prefix)
+}
+
+func to(x, y int) map[string]interface{} {
+	return map[string]interface{}{game.PositionParam: game.Position{X: x, Y: y}}
+}
+
+func TestReactiveStrikeOnLeavingReach(t *testing.T) {
+	gs, guard, goblin := newReactiveStrikeTest(t, 30)
+
+	// Only the first square of the Stride is within the guard's reach
+	act(t, gs, goblin, "Stride", to(5, 1), dice.ScriptedRoll{Sides: 20, Value: 5}, dice.ScriptedRoll{Sides: 8, Value: 4})
+	if goblin.HP != 26 {
+		t.Errorf("goblin has %d HP, want 26 after a Reactive Strike for 4", goblin.HP)
+	}
+	if guard.ReactionsRemaining != 0 {
+		t.Errorf("guard has %d reactions left, want 0", guard.ReactionsRemaining)
+	}
+	if got := gs.Grid.GetEntityPosition(goblin); got != (game.Position{X: 5, Y: 1}) {
+		t.Errorf("goblin ended its Stride at %v, want (5,1)", got)
+	}
+}
+
+func TestReactiveStrikeIgnoresStep(t *testing.T) {
+	gs, guard, goblin := newReactiveStrikeTest(t, 30)
+
+	// The script has no rolls, so any Strike would fail the test
+	act(t, gs, goblin, "Step", to(3, 1))
+	if guard.ReactionsRemaining != 1 {
+		t.Errorf("guard reacted to a Step")
+	}
+}
+
+func TestReactiveStrikeInterruptsMove(t *testing.T) {
+	gs, _, goblin := newReactiveStrikeTest(t, 4)
+
+	act(t, gs, goblin, "Stride", to(5, 1), dice.ScriptedRoll{Sides: 20, Value: 5}, dice.ScriptedRoll{Sides: 8, Value: 4})
+	if goblin.IsAlive() {
+		t.Fatalf("goblin survived the Reactive Strike on %d HP", goblin.HP)
+	}
+	if got := gs.Grid.GetEntityPosition(goblin); got != (game.Position{X: 2, Y: 1}) {
+		t.Errorf("goblin fell at %v, want the square it was leaving, (2,1)", got)
+	}
+}
+
+func TestReactiveStrikeOnManipulate(t *testing.T) {
+	tests := []struct {
+		name   string
+		d20    int
+		damage int
+		loaded bool
+	}{
+		{"hit lets the reload finish", 5, 4, true},
+		{"critical hit disrupts the reload", 15, 8, false},
+	}
+	for _, tt := range tests {
+		gs, guard, goblin := newReactiveStrikeTest(t, 30)
+
+		act(t, gs, goblin, "Reload", nil, dice.ScriptedRoll{Sides: 20, Value: tt.d20}, dice.ScriptedRoll{Sides: 8, Value: 4})
+		if got := 30 - goblin.HP; got != tt.damage {
+			t.Errorf("%s: goblin took %d damage, want %d", tt.name, got, tt.damage)
+		}
+		if guard.ReactionsRemaining != 0 {
+			t.Errorf("%s: guard has %d reactions left, want 0", tt.name, guard.ReactionsRemaining)
+		}
+		if goblin.Weapons[0].Loaded != tt.loaded {
+			t.Errorf("%s: crossbow loaded = %v, want %v", tt.name, goblin.Weapons[0].Loaded, tt.loaded)
+		}
+	}
+}
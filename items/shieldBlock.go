@@ -1,10 +1,11 @@
 package items
 
 import (
-	"fmt"
 	"pf2eEngine/game"
 )
 
+// ShieldBlock has its owner block physical damage from attacks with their
+// raised shield, using their reaction
 type ShieldBlock struct {
 	Owner *game.Entity
 }
@@ -12,25 +13,40 @@ type ShieldBlock struct {
 // Enforce ShieldBlock implements the Trigger interface
 var _ game.Trigger = ShieldBlock{}
 
+// Enforce ShieldBlock is offered to its owner as a reaction
+var _ game.ReactionTrigger = ShieldBlock{}
+
+// Enforce ShieldBlock follows its owner into copies of the game
+var _ game.CopyableReaction = ShieldBlock{}
+
+func (trigger ShieldBlock) Reactor() *game.Entity {
+	return trigger.Owner
+}
+
+// WithReactor gives the same reaction to e, for a copy of the game
+func (trigger ShieldBlock) WithReactor(e *game.Entity) game.ReactionTrigger {
+	return ShieldBlock{Owner: e}
+}
+
+func (trigger ShieldBlock) ReactionName() string {
+	return "Shield Block"
+}
+
 func (trigger ShieldBlock) Priority() int {
 	return 10 // Example priority: higher values execute earlier
 }
 
-func (trigger ShieldBlock) Condition(step game.Step) bool {
+func (trigger ShieldBlock) Condition(gs *game.GameState, step game.Step) bool {
 	if damageStep, ok := step.(game.BeforeDamageStep); ok {
-		return damageStep.Damage.Target != nil && damageStep.Damage.Target == trigger.Owner
+		return trigger.Owner.CanShieldBlock(damageStep.Damage)
 	}
 	return false
 }
 
-func (trigger ShieldBlock) Execute(step game.Step) {
-	if !trigger.Owner.UseReaction() {
-		fmt.Printf("%s has no reactions remaining to block!\n", trigger.Owner.Name)
-		return
-	}
-
+func (trigger ShieldBlock) Execute(gs *game.GameState, step game.Step) {
 	if damageStep, ok := step.(game.BeforeDamageStep); ok {
-		damageStep.Damage.Blocked += 5
-		fmt.Printf("%s uses Shield Block to block 5 damage.\n", trigger.Owner.Name)
+		if err := game.ShieldBlock(gs, trigger.Owner, damageStep.Damage); err != nil {
+			gs.Printf("%s cannot Shield Block: %v\n", trigger.Owner.Name, err)
+		}
 	}
 }
@@ -1,50 +1,31 @@
 package main
 
 import (
-	"math/rand"
+	"flag"
+	"fmt"
+	"os"
 	"pf2eEngine/controllerhttp"
 	"pf2eEngine/game"
 	"pf2eEngine/items"
-	"time"
+	dice "pf2eEngine/util"
 )
 
 func main() {
-	rand.Seed(time.Now().UnixNano()) // Seed the random number generator
-
-	d8Plus3Slashing := game.DamageRoll{
-		Die:   8,
-		Count: 1,
-		Bonus: 3,
-		Type:  game.Slashing,
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		simulate(os.Args[2:])
+		return
 	}
 
-	// Create combatants
-	warrior := game.NewEntity("Warrior", 30, 15, game.GoodGuys)
-	warriorAttack := game.BaseAttack{
-		Damage: []game.DamageRoll{d8Plus3Slashing},
-		Bonus:  5,
-	}
-	warrior.AddActionCard(game.NewStrikeCard(warriorAttack))
-	warrior.AddActionCard(game.NewStrideCard())
-
-	goblin1 := makeAGoblin("Goblin 1")
-	goblin2 := makeAGoblin("Goblin 2")
-	goblin3 := makeAGoblin("Goblin 3")
-	goblin4 := makeAGoblin("Goblin 4")
-
-	game.RegisterTrigger(items.ShieldBlock{Owner: warrior}, "BEFORE_DAMAGE")
-
-	// Position entities with more spacing to demonstrate grid-based movement
-	spawns := []game.Spawn{
-		{Unit: goblin1, Coordinates: [2]int{0, 0}}, // Top left
-		{Unit: goblin2, Coordinates: [2]int{6, 0}}, // Top right
-		{Unit: warrior, Coordinates: [2]int{3, 5}}, // Middle bottom - player is farther away
-		{Unit: goblin3, Coordinates: [2]int{0, 8}}, // Bottom left
-		{Unit: goblin4, Coordinates: [2]int{6, 8}}, // Bottom right
+	// A fixed seed replays the same combat given the same commands
+	seed := flag.Int64("seed", 0, "seed for the game's dice (0 picks a random seed)")
+	manualDice := flag.Bool("manual-dice", false, "ask players' clients for their physical die results")
+	flag.Parse()
+	if *seed == 0 {
+		*seed = dice.NewSeed()
 	}
 
 	// Initialize game state
-	gameState := game.NewGameState(spawns, 10, 10)
+	gameState := demoScenario().NewGame(*seed)
 
 	// Initialize player controller
 	playerController := game.NewPlayerController(gameState)
@@ -54,7 +35,11 @@ func main() {
 
 	// Connect game state to server
 	gameState.StepCallback = server.BroadcastGameStep
+	playerController.Prompter = server
 	server.GameState = gameState
+	if *manualDice {
+		gameState.Dice = game.NewManualRollSource(gameState, server)
+	}
 
 	// Start the server
 	server.Start()
@@ -66,13 +51,135 @@ func main() {
 	select {}
 }
 
+// simulate runs the demo scenario headless many times and prints a report
+func simulate(args []string) {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	combats := flags.Int("n", 1000, "number of combats to run")
+	seed := flags.Int64("seed", 1, "seed of the first combat")
+	maxRounds := flags.Int("max-rounds", 100, "rounds after which a combat counts as a draw")
+	workers := flags.Int("workers", 0, "combats to run in parallel (0 uses one per CPU)")
+	format := flags.String("format", "json", "report format: json or csv")
+	flags.Parse(args)
+
+	report := game.Simulate(demoScenario(), game.SimulationOptions{
+		Combats:   *combats,
+		Seed:      *seed,
+		MaxRounds: *maxRounds,
+		Workers:   *workers,
+	})
+
+	var err error
+	switch *format {
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	case "csv":
+		err = report.WriteCSV(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// demoScenario is a lone warrior against four goblins
+func demoScenario() game.Scenario {
+	return game.Scenario{
+		Name:       "Warrior vs Goblins",
+		GridWidth:  10,
+		GridHeight: 10,
+		Spawns: func() []game.Spawn {
+			// Create combatants
+			warrior := makeAWarrior()
+			warrior.AddActionCard(game.NewStrideCard())
+			warrior.AddActionCard(game.NewStepCard())
+			warrior.AddActionCard(game.NewLeapCard())
+			warrior.AddActionCard(game.NewDemoralizeCard())
+			warrior.AddActionCard(game.NewTripCard())
+			warrior.AddActionCard(game.NewStandCard())
+			warrior.AddActionCard(game.NewRaiseAShieldCard())
+
+			goblin1 := makeAGoblin("Goblin 1")
+			goblin2 := makeAGoblinArcher("Goblin 2")
+			goblin3 := makeAGoblinPyro("Goblin 3")
+			goblin4 := makeAGoblin("Goblin 4")
+
+			// Position entities with more spacing to demonstrate grid-based movement
+			return []game.Spawn{
+				{Unit: goblin1, Coordinates: [2]int{0, 0}}, // Top left
+				{Unit: goblin2, Coordinates: [2]int{6, 0}}, // Top right
+				{Unit: warrior, Coordinates: [2]int{3, 5}}, // Middle bottom - player is farther away
+				{Unit: goblin3, Coordinates: [2]int{0, 8}}, // Bottom left
+				{Unit: goblin4, Coordinates: [2]int{6, 8}}, // Bottom right
+			}
+		},
+		Setup: func(gs *game.GameState) {
+			for _, e := range gs.Initiative {
+				if e.Name == "Warrior" {
+					gs.RegisterTrigger(items.ShieldBlock{Owner: e}, game.BeforeDamage)
+					gs.RegisterTrigger(items.ReactiveStrike{Owner: e}, game.EntityMove)
+					gs.RegisterTrigger(items.ReactiveStrike{Owner: e}, game.StartAction)
+				}
+			}
+		},
+	}
+}
+
+// makeAWarrior builds a 1st-level fighter whose numbers come from its attributes and training
+func makeAWarrior() *game.Entity {
+	warrior := game.NewCharacter("Warrior", 1, 30, game.NewAttributes(4, 2, 2, 0, 1, 0), game.GoodGuys)
+	warrior.KeyAttribute = game.Strength
+	warrior.SetProficiency(game.StatAttack, game.Expert)
+	warrior.SetProficiency(game.StatAC, game.Trained)
+	warrior.SetProficiency(game.StatClassDC, game.Trained)
+	warrior.SetProficiency(game.StatPerception, game.Expert)
+	warrior.SetProficiency(game.Statistic(game.Fortitude), game.Expert)
+	warrior.SetProficiency(game.Statistic(game.Reflex), game.Expert)
+	warrior.SetProficiency(game.Statistic(game.Will), game.Trained)
+	warrior.SetProficiency(game.Statistic(game.Athletics), game.Trained)
+	warrior.SetProficiency(game.Statistic(game.Intimidation), game.Trained)
+	warrior.WearArmor(game.ChainMail)
+	if err := warrior.Wield(game.Longsword, 1); err != nil {
+		panic(err)
+	}
+	if err := warrior.EquipShield(game.SteelShield); err != nil {
+		panic(err)
+	}
+	return warrior
+}
+
 func makeAGoblin(name string) *game.Entity {
 	goblin := game.NewEntity(name, 20, 13, game.BadGuys)
-	goblinAttack := game.BaseAttack{
-		Damage: []game.DamageRoll{{Die: 6, Count: 1, Bonus: 1, Type: game.Piercing}},
-		Bonus:  3,
+	dogslicer := game.Dogslicer
+	dogslicer.Bonus = 3
+	dogslicer.DamageBonus = 1
+	if err := goblin.Wield(dogslicer, 1); err != nil {
+		panic(err)
 	}
-	goblin.AddActionCard(game.NewStrikeCard(goblinAttack))
 	goblin.AddActionCard(game.NewStrideCard()) // Add Stride action card
 	return goblin
 }
+
+// makeAGoblinPyro builds a goblin that sets its foes alight with the ignition cantrip
+func makeAGoblinPyro(name string) *game.Entity {
+	goblin := makeAGoblin(name)
+	goblin.SetSpellcasting(game.Spellcasting{Tradition: game.Arcane, Type: game.SpontaneousCasting, Attack: 5, DC: 15})
+	if err := goblin.LearnSpell(game.Ignition); err != nil {
+		panic(err)
+	}
+	return goblin
+}
+
+// makeAGoblinArcher builds a goblin that shoots from a distance with a shortbow
+func makeAGoblinArcher(name string) *game.Entity {
+	goblin := game.NewEntity(name, 20, 13, game.BadGuys)
+	shortbow := game.Shortbow
+	shortbow.Bonus = 3
+	if err := goblin.Wield(shortbow, 2); err != nil {
+		panic(err)
+	}
+	goblin.AddAmmunition("Arrows", 10)
+	goblin.AddActionCard(game.NewStrideCard())
+	return goblin
+}
@@ -1,22 +1,85 @@
 package dice
 
-import "math/rand"
+import (
+	"math/rand"
+	"time"
+)
+
+// Source produces die results. Every game owns its own Source so that a combat
+// can be replayed from its seed and two games never share a random stream.
+type Source interface {
+	Roll(sides int) int
+}
+
+// SeededSource is a Source backed by its own math/rand generator
+type SeededSource struct {
+	seed int64
+	src  *countedSource
+	rng  *rand.Rand
+}
+
+// countedSource counts the values drawn from a math/rand source, so that a
+// generator can be rebuilt at the same point in its stream
+type countedSource struct {
+	rand.Source
+	draws int
+}
+
+func (c *countedSource) Int63() int64 {
+	c.draws++
+	return c.Source.Int63()
+}
+
+// NewSeededSource creates a Source that always produces the same rolls for the same seed
+func NewSeededSource(seed int64) *SeededSource {
+	src := &countedSource{Source: rand.NewSource(seed)}
+	return &SeededSource{
+		seed: seed,
+		src:  src,
+		rng:  rand.New(src),
+	}
+}
+
+// Clone returns a source at the same point in the same stream, which
+// produces the same rolls as s from here on
+func (s *SeededSource) Clone() Source {
+	clone := NewSeededSource(s.seed)
+	for clone.src.draws < s.src.draws {
+		clone.src.Int63()
+	}
+	return clone
+}
+
+// NewSeed returns a seed suitable for a fresh, unpredictable game
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// Seed returns the seed the source was created with
+func (s *SeededSource) Seed() int64 {
+	return s.seed
+}
 
 // Roll simulates a dice roll of n sides
-func Roll(sides int) int {
-	return rand.Intn(sides) + 1
+func (s *SeededSource) Roll(sides int) int {
+	return s.rng.Intn(sides) + 1
+}
+
+// Roll simulates a dice roll of n sides using the given source
+func Roll(src Source, sides int) int {
+	return src.Roll(sides)
 }
 
 // RollMultiple rolls multiple dice of the same type and returns the total
-func RollMultiple(sides, count int) int {
+func RollMultiple(src Source, sides, count int) int {
 	total := 0
 	for i := 0; i < count; i++ {
-		total += Roll(sides)
+		total += src.Roll(sides)
 	}
 	return total
 }
 
 // RollWithModifier rolls a dice and adds a modifier to the result
-func RollWithModifier(sides, modifier int) int {
-	return Roll(sides) + modifier
+func RollWithModifier(src Source, sides, modifier int) int {
+	return src.Roll(sides) + modifier
 }
@@ -0,0 +1,359 @@
+package dice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expression is a parsed dice expression such as "2d6+1d4 fire+3" or "4d8kh3".
+// It is a flat sum of terms; each term is either a group of dice or a flat number.
+type Expression struct {
+	Source string
+	Terms  []Term
+}
+
+// Term is a single component of an Expression
+type Term struct {
+	Sign       int    // +1 or -1
+	Count      int    // Number of dice rolled; zero for a flat modifier
+	Sides      int    // Size of each die
+	Keep       int    // Number of dice kept; zero keeps them all
+	KeepLowest bool   // Keep the lowest dice instead of the highest
+	Constant   int    // Flat modifier, always positive; see Sign
+	Label      string // Lower-case label such as "fire" or "status"
+}
+
+// Limits on a single group of dice, so that expressions from clients can't
+// ask for more dice than can sensibly be rolled
+const (
+	MaxDiceCount = 100
+	MaxDieSize   = 1000
+)
+
+// Modifier is a flat number added to a roll, tagged with where it came from
+type Modifier struct {
+	Source string
+	Value  int
+}
+
+// Check is a parsed check expression: a single d20 plus modifiers
+type Check struct {
+	Modifiers []Modifier
+}
+
+// ParseError describes where and why an expression could not be parsed
+type ParseError struct {
+	Input string
+	Pos   int
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("dice: %s at position %d in %q", e.Msg, e.Pos, e.Input)
+}
+
+// IsDice reports whether the term rolls dice rather than adding a flat number
+func (t Term) IsDice() bool {
+	return t.Count > 0
+}
+
+// Modifier returns the signed value of a flat term
+func (t Term) Modifier() int {
+	return t.Sign * t.Constant
+}
+
+// String renders the term without its sign
+func (t Term) String() string {
+	var sb strings.Builder
+	if t.IsDice() {
+		fmt.Fprintf(&sb, "%dd%d", t.Count, t.Sides)
+		if t.Keep > 0 {
+			if t.KeepLowest {
+				fmt.Fprintf(&sb, "kl%d", t.Keep)
+			} else {
+				fmt.Fprintf(&sb, "kh%d", t.Keep)
+			}
+		}
+	} else {
+		sb.WriteString(strconv.Itoa(t.Constant))
+	}
+	if t.Label != "" {
+		sb.WriteString(" ")
+		sb.WriteString(t.Label)
+	}
+	return sb.String()
+}
+
+// String renders the expression in canonical form
+func (e Expression) String() string {
+	var sb strings.Builder
+	for i, t := range e.Terms {
+		if t.Sign < 0 {
+			sb.WriteString("-")
+		} else if i > 0 {
+			sb.WriteString("+")
+		}
+		sb.WriteString(t.String())
+	}
+	return sb.String()
+}
+
+// Roll rolls every term of the expression into a RollRecord
+func (e Expression) Roll(src Source) RollRecord {
+	record := RollRecord{Expression: e.String()}
+	for _, t := range e.Terms {
+		if t.IsDice() {
+			record.AddSignedDice(src, t.Sign, t.Count, t.Sides, t.Keep, t.KeepLowest)
+		} else {
+			record.AddModifier(t.Label, t.Modifier())
+		}
+	}
+	return record
+}
+
+// Total returns the sum of all modifiers on the check
+func (c Check) Total() int {
+	total := 0
+	for _, m := range c.Modifiers {
+		total += m.Value
+	}
+	return total
+}
+
+// ParseCheck parses a check such as "1d20+7" or "1d20+5 weapon-5 map".
+// The expression must contain exactly one 1d20; every other term must be flat,
+// and labels become the modifiers' sources.
+func ParseCheck(input string) (Check, error) {
+	expr, err := Parse(input)
+	if err != nil {
+		return Check{}, err
+	}
+	check := Check{}
+	d20s := 0
+	for _, t := range expr.Terms {
+		if t.IsDice() {
+			if t.Count != 1 || t.Sides != 20 || t.Sign < 0 {
+				return Check{}, &ParseError{Input: input, Msg: fmt.Sprintf("checks roll a single d20, not %s", t.String())}
+			}
+			d20s++
+			continue
+		}
+		check.Modifiers = append(check.Modifiers, Modifier{Source: t.Label, Value: t.Modifier()})
+	}
+	if d20s != 1 {
+		return Check{}, &ParseError{Input: input, Msg: "checks must roll exactly one d20"}
+	}
+	return check, nil
+}
+
+// Parse parses a dice expression.
+//
+// Terms are joined by + or -. A dice term is [count]d<sides> with an optional
+// kh<n> or kl<n> suffix; a flat term is a number. Any term may be followed by a
+// space and a label of one or more words ("fire", "persistent bleed"). A label also applies
+// to the unlabelled terms before it, so "2d6+1d4 fire" is all fire, and terms
+// after the last label inherit it, so "1d8 slashing+3" adds 3 slashing.
+func Parse(input string) (Expression, error) {
+	p := parser{input: input}
+	expr := Expression{Source: input}
+
+	p.skipSpace()
+	if p.done() {
+		return Expression{}, p.errorf("empty expression")
+	}
+
+	sign := 1
+	if c := p.peek(); c == '+' || c == '-' {
+		if c == '-' {
+			sign = -1
+		}
+		p.pos++
+	}
+	for {
+		term, err := p.term(sign)
+		if err != nil {
+			return Expression{}, err
+		}
+		expr.Terms = append(expr.Terms, term)
+
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		switch p.peek() {
+		case '+':
+			sign = 1
+		case '-':
+			sign = -1
+		default:
+			return Expression{}, p.errorf("expected + or -, found %q", p.peek())
+		}
+		p.pos++
+	}
+
+	applyLabels(expr.Terms)
+	return expr, nil
+}
+
+// applyLabels spreads each label back over the unlabelled terms before it and
+// forward over any trailing unlabelled terms
+func applyLabels(terms []Term) {
+	start := 0
+	last := ""
+	for i := range terms {
+		if terms[i].Label == "" {
+			continue
+		}
+		for j := start; j < i; j++ {
+			terms[j].Label = terms[i].Label
+		}
+		start = i + 1
+		last = terms[i].Label
+	}
+	for j := start; j < len(terms); j++ {
+		terms[j].Label = last
+	}
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Input: p.input, Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) number() (int, bool) {
+	start := p.pos
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, false
+	}
+	n, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false
+	}
+	return n, true
+}
+
+func (p *parser) term(sign int) (Term, error) {
+	p.skipSpace()
+	if p.done() {
+		return Term{}, p.errorf("expected a number or dice after operator")
+	}
+	term := Term{Sign: sign}
+	start := p.pos
+	n, hasNumber := p.number()
+
+	if !p.done() && (p.peek() == 'd' || p.peek() == 'D') {
+		p.pos++
+		if !hasNumber {
+			n = 1
+		}
+		if n <= 0 {
+			return Term{}, &ParseError{Input: p.input, Pos: start, Msg: "dice count must be at least 1"}
+		}
+		if n > MaxDiceCount {
+			return Term{}, &ParseError{Input: p.input, Pos: start, Msg: fmt.Sprintf("dice count must be at most %d, got %d", MaxDiceCount, n)}
+		}
+		sidesPos := p.pos
+		sides, ok := p.number()
+		if !ok {
+			return Term{}, p.errorf("expected die size after 'd'")
+		}
+		if sides < 2 {
+			return Term{}, &ParseError{Input: p.input, Pos: sidesPos, Msg: fmt.Sprintf("die size must be at least 2, got %d", sides)}
+		}
+		if sides > MaxDieSize {
+			return Term{}, &ParseError{Input: p.input, Pos: sidesPos, Msg: fmt.Sprintf("die size must be at most %d, got %d", MaxDieSize, sides)}
+		}
+		term.Count = n
+		term.Sides = sides
+		if err := p.keep(&term); err != nil {
+			return Term{}, err
+		}
+	} else if hasNumber {
+		term.Constant = n
+	} else {
+		return Term{}, p.errorf("expected a number or dice, found %q", p.peek())
+	}
+
+	label, err := p.label()
+	if err != nil {
+		return Term{}, err
+	}
+	term.Label = label
+	return term, nil
+}
+
+func (p *parser) keep(term *Term) error {
+	if p.done() || p.peek() != 'k' {
+		return nil
+	}
+	p.pos++
+	switch {
+	case !p.done() && p.peek() == 'h':
+	case !p.done() && p.peek() == 'l':
+		term.KeepLowest = true
+	default:
+		return p.errorf("expected 'h' or 'l' after 'k'")
+	}
+	p.pos++
+	keepPos := p.pos
+	keep, ok := p.number()
+	if !ok {
+		return p.errorf("expected number of dice to keep")
+	}
+	if keep < 1 || keep > term.Count {
+		return &ParseError{Input: p.input, Pos: keepPos, Msg: fmt.Sprintf("cannot keep %d of %d dice", keep, term.Count)}
+	}
+	term.Keep = keep
+	return nil
+}
+
+// label reads zero or more space separated words up to the next operator.
+// The first word must be separated from the term by a space.
+func (p *parser) label() (string, error) {
+	if !p.done() && isLabelChar(p.peek()) {
+		return "", p.errorf("expected a space before label")
+	}
+	var words []string
+	for {
+		p.skipSpace()
+		start := p.pos
+		for !p.done() && isLabelChar(p.peek()) {
+			p.pos++
+		}
+		if start == p.pos {
+			break
+		}
+		words = append(words, strings.ToLower(p.input[start:p.pos]))
+	}
+	if !p.done() && p.peek() != '+' && p.peek() != '-' {
+		return "", p.errorf("unexpected character %q", p.peek())
+	}
+	return strings.Join(words, " "), nil
+}
+
+func isLabelChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
@@ -0,0 +1,159 @@
+package dice
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAccepts(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Term
+	}{
+		{"7", []Term{{Sign: 1, Constant: 7}}},
+		{"-2", []Term{{Sign: -1, Constant: 2}}},
+		{"d20", []Term{{Sign: 1, Count: 1, Sides: 20}}},
+		{"2D6", []Term{{Sign: 1, Count: 2, Sides: 6}}},
+		{"1d8+4", []Term{{Sign: 1, Count: 1, Sides: 8}, {Sign: 1, Constant: 4}}},
+		{" 2d6 - 1 ", []Term{{Sign: 1, Count: 2, Sides: 6}, {Sign: -1, Constant: 1}}},
+		{"4d6kh3", []Term{{Sign: 1, Count: 4, Sides: 6, Keep: 3}}},
+		{"2d20kl1", []Term{{Sign: 1, Count: 2, Sides: 20, Keep: 1, KeepLowest: true}}},
+		{"3d6kh3", []Term{{Sign: 1, Count: 3, Sides: 6, Keep: 3}}},
+		{"100d1000", []Term{{Sign: 1, Count: 100, Sides: 1000}}},
+		{"1d2", []Term{{Sign: 1, Count: 1, Sides: 2}}},
+		{"2d6+1d4 Fire+3", []Term{
+			{Sign: 1, Count: 2, Sides: 6, Label: "fire"},
+			{Sign: 1, Count: 1, Sides: 4, Label: "fire"},
+			{Sign: 1, Constant: 3, Label: "fire"},
+		}},
+		{"1d8 slashing+1d6 persistent bleed", []Term{
+			{Sign: 1, Count: 1, Sides: 8, Label: "slashing"},
+			{Sign: 1, Count: 1, Sides: 6, Label: "persistent bleed"},
+		}},
+		{"1d20+5 weapon-5 map", []Term{
+			{Sign: 1, Count: 1, Sides: 20, Label: "weapon"},
+			{Sign: 1, Constant: 5, Label: "weapon"},
+			{Sign: -1, Constant: 5, Label: "map"},
+		}},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(expr.Terms, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.input, expr.Terms, tt.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		input string
+		msg   string
+	}{
+		{"", "empty expression"},
+		{"   ", "empty expression"},
+		{"1d6+", "expected a number or dice after operator"},
+		{"+", "expected a number or dice after operator"},
+		{"0d6", "dice count must be at least 1"},
+		{"101d6", "dice count must be at most 100, got 101"},
+		{"1d1", "die size must be at least 2, got 1"},
+		{"1d0", "die size must be at least 2, got 0"},
+		{"1d1001", "die size must be at most 1000, got 1001"},
+		{"1d", "expected die size after 'd'"},
+		{"2d6kh", "expected number of dice to keep"},
+		{"2d6kh3", "cannot keep 3 of 2 dice"},
+		{"2d6kl0", "cannot keep 0 of 2 dice"},
+		{"2d6*2", "unexpected character '*'"},
+		{"1d6 fire 3", "unexpected character '3'"},
+		{"2d6 kh1", "unexpected character '1'"},
+		{"4d6KH3", "expected a space before label"},
+		{"1d8kx", "expected 'h' or 'l' after 'k'"},
+		{"1d8k", "expected 'h' or 'l' after 'k'"},
+		{"1d6fire", "expected a space before label"},
+		{"2d6kh1fire", "expected a space before label"},
+		{"*", "expected a number or dice, found '*'"},
+		{"99999999999999999999d6", "expected a number or dice"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) error = %v, want a *ParseError", tt.input, err)
+			continue
+		}
+		if !strings.HasPrefix(parseErr.Msg, tt.msg) {
+			t.Errorf("Parse(%q) error = %q, want %q", tt.input, parseErr.Msg, tt.msg)
+		}
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+	}{
+		{"1d6+101d6", 4},
+		{"2d6+1d1001", 6},
+		{"4d6kh5", 5},
+		{"1d6 + x*", 6},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) error = %v, want a *ParseError", tt.input, err)
+			continue
+		}
+		if parseErr.Pos != tt.pos {
+			t.Errorf("Parse(%q) error at position %d, want %d", tt.input, parseErr.Pos, tt.pos)
+		}
+	}
+}
+
+func TestExpressionString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"d20", "1d20"},
+		{"-2+1d4", "-2+1d4"},
+		{"4d6kh3 + 2", "4d6kh3+2"},
+		{"2d20kl1", "2d20kl1"},
+		{"1d8 slashing+3", "1d8 slashing+3 slashing"},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseCheck(t *testing.T) {
+	check, err := ParseCheck("1d20+5 weapon-5 map+1")
+	if err != nil {
+		t.Fatalf("ParseCheck returned error: %v", err)
+	}
+	want := []Modifier{{Source: "weapon", Value: 5}, {Source: "map", Value: -5}, {Source: "map", Value: 1}}
+	if !reflect.DeepEqual(check.Modifiers, want) {
+		t.Errorf("ParseCheck modifiers = %+v, want %+v", check.Modifiers, want)
+	}
+	if check.Total() != 1 {
+		t.Errorf("ParseCheck total = %d, want 1", check.Total())
+	}
+
+	for _, input := range []string{"7", "2d20", "1d20+1d20", "1d6+3", "-1d20", "1d20+1d4"} {
+		if _, err := ParseCheck(input); err == nil {
+			t.Errorf("ParseCheck(%q) succeeded, want an error", input)
+		}
+	}
+}
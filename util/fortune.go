@@ -0,0 +1,51 @@
+package dice
+
+// Fortune describes whether a roll is made twice and which result is kept
+type Fortune int
+
+const (
+	NoFortune      Fortune = iota // Roll once
+	FortuneRoll                   // Roll twice and keep the higher result
+	MisfortuneRoll                // Roll twice and keep the lower result
+)
+
+func (f Fortune) String() string {
+	switch f {
+	case FortuneRoll:
+		return "fortune"
+	case MisfortuneRoll:
+		return "misfortune"
+	default:
+		return ""
+	}
+}
+
+// ResolveFortune applies the rule that fortune and misfortune effects cancel
+// each other out. Several fortune effects still only roll twice.
+func ResolveFortune(fortune, misfortune bool) Fortune {
+	switch {
+	case fortune && !misfortune:
+		return FortuneRoll
+	case misfortune && !fortune:
+		return MisfortuneRoll
+	default:
+		return NoFortune
+	}
+}
+
+// RollWithFortune makes the roll once, or twice when f calls for it, and returns
+// the kept record with the other one attached as discarded
+func RollWithFortune(f Fortune, roll func() RollRecord) RollRecord {
+	first := roll()
+	if f == NoFortune {
+		return first
+	}
+	second := roll()
+	kept, discarded := first, second
+	if (f == FortuneRoll && second.Total > first.Total) || (f == MisfortuneRoll && second.Total < first.Total) {
+		kept, discarded = second, first
+	}
+	kept.Fortune = f
+	kept.Discarded = append(kept.Discarded, discarded)
+	return kept
+}
@@ -0,0 +1,135 @@
+package dice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DieResult is a single die rolled as part of a RollRecord
+type DieResult struct {
+	Sides   int
+	Value   int
+	Dropped bool // Discarded by a keep rule and not counted in the total
+}
+
+// RollRecord keeps everything about a roll: the expression, every die face,
+// the modifiers broken down by source and the final total
+type RollRecord struct {
+	Expression string
+	Dice       []DieResult
+	Modifiers  []Modifier
+	Total      int
+	Fortune    Fortune      // Set when the roll was made twice
+	Discarded  []RollRecord // The roll thrown away by fortune or misfortune
+}
+
+// RollDice rolls count dice and marks the ones discarded by the keep rule as dropped
+func RollDice(src Source, count, sides, keep int, keepLowest bool) []DieResult {
+	results := make([]DieResult, count)
+	for i := range results {
+		results[i] = DieResult{Sides: sides, Value: src.Roll(sides)}
+	}
+	if keep <= 0 || keep >= count {
+		return results
+	}
+	order := make([]int, count)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if keepLowest {
+			return results[order[a]].Value < results[order[b]].Value
+		}
+		return results[order[a]].Value > results[order[b]].Value
+	})
+	for _, i := range order[keep:] {
+		results[i].Dropped = true
+	}
+	return results
+}
+
+// AddDice rolls dice into the record and returns the sum of the kept faces
+func (r *RollRecord) AddDice(src Source, count, sides, keep int, keepLowest bool) int {
+	return r.AddSignedDice(src, 1, count, sides, keep, keepLowest)
+}
+
+// AddSignedDice is AddDice for dice that may be subtracted from the total
+func (r *RollRecord) AddSignedDice(src Source, sign, count, sides, keep int, keepLowest bool) int {
+	kept := 0
+	for _, d := range RollDice(src, count, sides, keep, keepLowest) {
+		r.Dice = append(r.Dice, d)
+		if !d.Dropped {
+			kept += d.Value
+		}
+	}
+	r.Total += sign * kept
+	return kept
+}
+
+// AddModifier adds a flat modifier to the record. Zero modifiers are skipped
+// so breakdowns only show what actually changed the roll.
+func (r *RollRecord) AddModifier(source string, value int) {
+	if value == 0 {
+		return
+	}
+	r.Modifiers = append(r.Modifiers, Modifier{Source: source, Value: value})
+	r.Total += value
+}
+
+// Faces returns the values of the kept dice
+func (r RollRecord) Faces() []int {
+	var faces []int
+	for _, d := range r.Dice {
+		if !d.Dropped {
+			faces = append(faces, d.Value)
+		}
+	}
+	return faces
+}
+
+// String renders the record for a combat log, e.g. "d20=14 +5 (weapon) -5 (MAP) = 14"
+func (r RollRecord) String() string {
+	var parts []string
+	for i := 0; i < len(r.Dice); {
+		j := i
+		for j < len(r.Dice) && r.Dice[j].Sides == r.Dice[i].Sides {
+			j++
+		}
+		parts = append(parts, formatDice(r.Dice[i:j]))
+		i = j
+	}
+	for _, m := range r.Modifiers {
+		sign := "+"
+		value := m.Value
+		if value < 0 {
+			sign = "-"
+			value = -value
+		}
+		if m.Source == "" {
+			parts = append(parts, fmt.Sprintf("%s%d", sign, value))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s%d (%s)", sign, value, m.Source))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("= %d", r.Total))
+	for _, d := range r.Discarded {
+		parts = append(parts, fmt.Sprintf("[%s, discarded %s]", r.Fortune, d.String()))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatDice(group []DieResult) string {
+	if len(group) == 1 && !group[0].Dropped {
+		return fmt.Sprintf("d%d=%d", group[0].Sides, group[0].Value)
+	}
+	faces := make([]string, len(group))
+	for i, d := range group {
+		if d.Dropped {
+			faces[i] = fmt.Sprintf("(%d)", d.Value)
+		} else {
+			faces[i] = fmt.Sprint(d.Value)
+		}
+	}
+	return fmt.Sprintf("%dd%d=[%s]", len(group), group[0].Sides, strings.Join(faces, ","))
+}
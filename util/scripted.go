@@ -0,0 +1,83 @@
+package dice
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ScriptedRoll is a predetermined die result. Sides of zero matches any die.
+type ScriptedRoll struct {
+	Sides int
+	Value int
+}
+
+// ScriptError reports a scripted roll that could not be played back
+type ScriptError struct {
+	Sides int
+	Msg   string
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("dice: scripted d%d: %s", e.Sides, e.Msg)
+}
+
+// ScriptedSource plays back a queue of predetermined results, e.g. "the next
+// d20 is a 20, then a 3". Once the queue is empty it rolls with Fallback, or
+// panics when there is no fallback so a test can't silently go off script.
+type ScriptedSource struct {
+	Fallback Source
+
+	mu    sync.Mutex
+	queue []ScriptedRoll
+}
+
+// NewScriptedSource creates a source that plays back rolls before using fallback
+func NewScriptedSource(fallback Source, rolls ...ScriptedRoll) *ScriptedSource {
+	return &ScriptedSource{Fallback: fallback, queue: rolls}
+}
+
+// Queue appends results for dice of the given size to the script
+func (s *ScriptedSource) Queue(sides int, values ...int) error {
+	for _, v := range values {
+		if v < 1 || (sides > 0 && v > sides) {
+			return &ScriptError{Sides: sides, Msg: fmt.Sprintf("%d is not a possible result", v)}
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range values {
+		s.queue = append(s.queue, ScriptedRoll{Sides: sides, Value: v})
+	}
+	return nil
+}
+
+// Remaining returns how many scripted rolls have not been used yet
+func (s *ScriptedSource) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// Roll returns the next scripted result. It panics with a *ScriptError if the
+// next result was scripted for a different die or doesn't fit on this one.
+func (s *ScriptedSource) Roll(sides int) int {
+	s.mu.Lock()
+	if len(s.queue) == 0 {
+		s.mu.Unlock()
+		if s.Fallback == nil {
+			panic(&ScriptError{Sides: sides, Msg: "script ran out of rolls"})
+		}
+		return s.Fallback.Roll(sides)
+	}
+	next := s.queue[0]
+	s.queue = s.queue[1:]
+	s.mu.Unlock()
+
+	if next.Sides != 0 && next.Sides != sides {
+		panic(&ScriptError{Sides: sides, Msg: fmt.Sprintf("next scripted roll is for a d%d", next.Sides)})
+	}
+	if next.Value > sides {
+		panic(&ScriptError{Sides: sides, Msg: fmt.Sprintf("scripted result %d does not fit", next.Value)})
+	}
+	return next.Value
+}
@@ -0,0 +1,101 @@
+package dice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// scriptPanic runs roll and returns the *ScriptError it panics with, if any
+func scriptPanic(roll func()) (err *ScriptError) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*ScriptError)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+	roll()
+	return nil
+}
+
+func TestScriptedSourcePlayback(t *testing.T) {
+	src := NewScriptedSource(nil, ScriptedRoll{Sides: 20, Value: 20}, ScriptedRoll{Value: 3})
+	if err := src.Queue(6, 1, 6); err != nil {
+		t.Fatalf("Queue returned error: %v", err)
+	}
+	rolls := []struct{ sides, want int }{{20, 20}, {8, 3}, {6, 1}, {6, 6}}
+	for i, r := range rolls {
+		if got := src.Roll(r.sides); got != r.want {
+			t.Errorf("roll %d: d%d = %d, want %d", i, r.sides, got, r.want)
+		}
+		if got, want := src.Remaining(), len(rolls)-i-1; got != want {
+			t.Errorf("roll %d: %d rolls remaining, want %d", i, got, want)
+		}
+	}
+}
+
+func TestScriptedSourceFallback(t *testing.T) {
+	seeded := NewSeededSource(5)
+	want := NewSeededSource(5)
+	src := NewScriptedSource(seeded, ScriptedRoll{Sides: 20, Value: 1})
+	if got := src.Roll(20); got != 1 {
+		t.Errorf("scripted d20 = %d, want 1", got)
+	}
+	for i := 0; i < 5; i++ {
+		if got, w := src.Roll(12), want.Roll(12); got != w {
+			t.Errorf("fallback roll %d = %d, want %d from the fallback source", i, got, w)
+		}
+	}
+}
+
+func TestScriptedSourceRunsOut(t *testing.T) {
+	src := NewScriptedSource(nil, ScriptedRoll{Sides: 4, Value: 2})
+	src.Roll(4)
+	err := scriptPanic(func() { src.Roll(4) })
+	if err == nil {
+		t.Fatal("rolling past the end of the script did not panic")
+	}
+	if err.Sides != 4 || !strings.Contains(err.Error(), "ran out") {
+		t.Errorf("ran out with %v", err)
+	}
+}
+
+func TestScriptedSourceMismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		roll  ScriptedRoll
+		sides int
+		msg   string
+	}{
+		{"wrong die", ScriptedRoll{Sides: 20, Value: 5}, 6, "next scripted roll is for a d20"},
+		{"too big for any-size roll", ScriptedRoll{Value: 9}, 8, "scripted result 9 does not fit"},
+	}
+	for _, tt := range tests {
+		src := NewScriptedSource(NewSeededSource(1), tt.roll)
+		err := scriptPanic(func() { src.Roll(tt.sides) })
+		if err == nil {
+			t.Errorf("%s: rolling a d%d did not panic", tt.name, tt.sides)
+			continue
+		}
+		if err.Sides != tt.sides || err.Msg != tt.msg {
+			t.Errorf("%s: got %v, want d%d: %s", tt.name, err, tt.sides, tt.msg)
+		}
+	}
+}
+
+func TestScriptedSourceQueueRejectsImpossibleResults(t *testing.T) {
+	src := NewScriptedSource(nil)
+	for _, tt := range []struct{ sides, value int }{{6, 7}, {6, 0}, {0, -1}} {
+		err := src.Queue(tt.sides, 1, tt.value)
+		var scriptErr *ScriptError
+		if !errors.As(err, &scriptErr) {
+			t.Errorf("Queue(%d, 1, %d) error = %v, want a *ScriptError", tt.sides, tt.value, err)
+		}
+	}
+	if src.Remaining() != 0 {
+		t.Errorf("a rejected Queue call left %d rolls in the script", src.Remaining())
+	}
+}